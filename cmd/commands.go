@@ -1,76 +1,176 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/abiosoft/ishell"
 	"github.com/rodcorsi/ecalc"
+	"github.com/rodcorsi/ecalc/esolver"
 )
 
 const version = "v0.4"
 
+// maxDMSPrecision limits the decimals of the seconds of the dms command
+const maxDMSPrecision = 12
+
 const hello = "Ecalc (" + version + `) - Engineer command calculator
 type 'help' for more informations or 'exit' to leave
 `
 
-const helpText = `Ecalc - Engineer command calculator
-Expressions example:
-    5+2
-    15*pi
-    tan45
-    (4+5)*cos45d25m33.15s
-    *2
-	/3
-CTRL+C:
-    Copy result to clipboard
-Commands:
-    exit   terminate this
-    help   show this text
-    dms    print last result to Degree Minutes Seconds
-    clear  clear all screen
-    cls    same as clear command
-    set    define variable with last value
-    cp     copy to clipboard
-    update update ecalc to the latest version
-Operator:
-    + - * / ^
-Functions:
-    ln abs cos sin tan acos asin atan sqrt cbrt ceil floor
-Constants:
-    e pi phi sqrtii sqrte sqrtpi sqrtphi ans in
-ANS:
-    you can use an special variable 'ans' to use the last result on your expression
-`
-
 var reValidSetName = regexp.MustCompile(`^[a-zA-Z]+$`)
 
-func addCommands(shell *ishell.Shell, ecalc *ecalc.ECalc) {
+func addCommands(shell *ishell.Shell, ecalc *ecalc.ECalc, cfg *config, cfgPath string) {
 	shell.AddCmd(&ishell.Cmd{
 		Name: "help",
-		Help: "Help",
+		Help: commandHelp("help"),
 		Func: func(c *ishell.Context) {
-			c.Println(helpText)
+			text, err := help(ecalc, c.Args)
+			if err != nil {
+				c.Println(fmtError.Sprint(err))
+				return
+			}
+			c.Println(text)
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "apropos",
+		Help: commandHelp("apropos"),
+		Func: func(c *ishell.Context) {
+			if len(c.Args) == 0 {
+				c.Println("Usage: apropos <word>")
+				return
+			}
+			text, err := apropos(strings.Join(c.Args, " "))
+			if err != nil {
+				c.Println(fmtError.Sprint(err))
+				return
+			}
+			c.Println(text)
 		},
 	})
 	shell.AddCmd(&ishell.Cmd{
 		Name: "cls",
-		Help: "clear screen",
+		Help: commandHelp("cls"),
 		Func: func(c *ishell.Context) {
 			c.ClearScreen()
 		},
 	})
 	shell.AddCmd(&ishell.Cmd{
 		Name: "dms",
-		Help: "print last result to Degree Minutes Seconds",
+		Help: commandHelp("dms"),
 		Func: func(c *ishell.Context) {
+			if err := setDMSFormat(ecalc, c.Args); err != nil {
+				c.Println(fmtError.Sprint(err))
+				return
+			}
+			ecalc.Result.DMSPrecision = ecalc.DMSPrecision
+			ecalc.Result.DMSSymbols = ecalc.DMSSymbols
 			ecalc.Result.Degree = true
 			c.Println(resultLine(ecalc.Result))
 		},
 	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "hms",
+		Help: commandHelp("hms"),
+		Func: func(c *ishell.Context) {
+			ecalc.Result.Time = true
+			c.Println(resultLine(ecalc.Result))
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name:    "info",
+		Aliases: []string{"show"},
+		Help:    commandHelp("info"),
+		Func: func(c *ishell.Context) {
+			switch strings.Join(c.Args, "") {
+			case "on":
+				ecalc.Info = true
+			case "off":
+				ecalc.Info = false
+			case "":
+			default:
+				c.Println("Usage: info [on|off]")
+				return
+			}
+			lines := representationLines(ecalc.Result)
+			if lines == "" {
+				c.Println(resultLine(ecalc.Result))
+				return
+			}
+			c.Println(lines)
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "prompt",
+		Help: commandHelp("prompt"),
+		Func: func(c *ishell.Context) {
+			template := strings.Join(c.Args, " ")
+			switch template {
+			case "":
+				c.Println(promptTemplate)
+				return
+			case "default":
+				template = defaultPrompt
+			}
+			if err := checkPrompt(template); err != nil {
+				c.Println(fmtError.Sprint(err))
+				return
+			}
+			promptTemplate = template
+			c.SetPrompt(prompt(ecalc))
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "angle",
+		Help: commandHelp("angle"),
+		Func: func(c *ishell.Context) {
+			if len(c.Args) == 0 {
+				c.Println(ecalc.AngleUnit())
+				return
+			}
+			u, err := esolver.ParseAngleUnit(strings.Join(c.Args, ""))
+			if err != nil {
+				c.Println(fmtError.Sprint(err))
+				return
+			}
+			ecalc.SetAngleUnit(u)
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "strictness",
+		Help: commandHelp("strictness"),
+		Func: func(c *ishell.Context) {
+			if len(c.Args) == 0 {
+				c.Println(ecalc.Strictness())
+				return
+			}
+			s, err := esolver.ParseStrictness(strings.Join(c.Args, ""))
+			if err != nil {
+				c.Println(fmtError.Sprint(err))
+				return
+			}
+			ecalc.SetStrictness(s)
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "config",
+		Help: commandHelp("config"),
+		Func: func(c *ishell.Context) {
+			if err := configCommand(c, ecalc, cfg, cfgPath); err != nil {
+				c.Println(fmtError.Sprint(err))
+			}
+		},
+	})
 	shell.AddCmd(&ishell.Cmd{
 		Name: "set",
-		Help: "define variable with last value",
+		Help: commandHelp("set"),
 		Func: func(c *ishell.Context) {
 			expr := strings.Join(c.Args, " ")
 			varName := strings.TrimSpace(expr)
@@ -80,14 +180,13 @@ func addCommands(shell *ishell.Shell, ecalc *ecalc.ECalc) {
 				c.Printf("Invalid variable name '%v'. Must be just letters", expr)
 				return
 			}
-			value := ecalc.Result.Value
-			ecalc.AddConstant(varName, value)
+			ecalc.AddResult(varName, ecalc.Result)
 			c.Printf("%v => %v", varName, formatResult(ecalc.Result))
 		},
 	})
 	shell.AddCmd(&ishell.Cmd{
 		Name: "cp",
-		Help: "Copy to clipboard",
+		Help: commandHelp("cp"),
 		Func: func(c *ishell.Context) {
 			value, err := copyToClipboard(c, ecalc)
 			if err != nil {
@@ -97,9 +196,237 @@ func addCommands(shell *ishell.Shell, ecalc *ecalc.ECalc) {
 			c.Printf("'%v' copied to clipboard!", value)
 		},
 	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "solve",
+		Help: commandHelp("solve"),
+		Func: func(c *ishell.Context) {
+			result := ecalc.Eval(solveExpression(strings.Join(c.Args, " ")))
+			c.Println(resultLine(result))
+			c.SetPrompt(prompt(ecalc))
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "complex",
+		Help: commandHelp("complex"),
+		Func: func(c *ishell.Context) {
+			switch strings.Join(c.Args, "") {
+			case "on":
+				ecalc.SetComplexMode(true)
+			case "off":
+				ecalc.SetComplexMode(false)
+			case "":
+				ecalc.SetComplexMode(!ecalc.ComplexMode())
+			default:
+				c.Println("Usage: complex on|off")
+				return
+			}
+			if ecalc.ComplexMode() {
+				c.Println("complex mode on")
+			} else {
+				c.Println("complex mode off")
+			}
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "polar",
+		Help: commandHelp("polar"),
+		Func: func(c *ishell.Context) {
+			ecalc.Polar = true
+			ecalc.Result.Polar = true
+			c.Println(resultLine(ecalc.Result))
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "rect",
+		Help: commandHelp("rect"),
+		Func: func(c *ishell.Context) {
+			ecalc.Polar = false
+			ecalc.Result.Polar = false
+			c.Println(resultLine(ecalc.Result))
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "money",
+		Help: commandHelp("money"),
+		Func: func(c *ishell.Context) {
+			switch strings.Join(c.Args, "") {
+			case "on":
+				ecalc.Money = true
+			case "off":
+				ecalc.Money = false
+			case "":
+				ecalc.Money = !ecalc.Money
+			default:
+				c.Println("Usage: money on|off")
+				return
+			}
+			ecalc.Result.Money = ecalc.Money
+			c.Println(resultLine(ecalc.Result))
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "table",
+		Help: commandHelp("table"),
+		Func: func(c *ishell.Context) {
+			path, name, mode, err := parseTableLoad(c.Args)
+			if err != nil {
+				c.Println(fmtError.Sprint(err))
+				return
+			}
+			table, err := ecalc.LoadTable(path, name, mode)
+			if err != nil {
+				c.Println(fmtError.Sprint(err))
+				return
+			}
+			c.Println(tableLine(table))
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "tables",
+		Help: commandHelp("tables"),
+		Func: func(c *ishell.Context) {
+			tables := ecalc.Tables()
+			if len(tables) == 0 {
+				c.Println("no tables, load one with: table load <file.csv> as <name>")
+				return
+			}
+			for _, t := range tables {
+				c.Println(tableLine(t))
+			}
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "data",
+		Help: commandHelp("data"),
+		Func: func(c *ishell.Context) {
+			path, name, err := parseDataArgs(c.Args)
+			if err != nil {
+				c.Println(fmtError.Sprint(err))
+				return
+			}
+			var r io.Reader
+			if path == "" {
+				c.Println("type the numbers, an empty line ends")
+				r = strings.NewReader(c.ReadMultiLinesFunc(func(line string) bool { return line != "" }))
+			} else {
+				f, err := os.Open(path)
+				if err != nil {
+					c.Println(fmtError.Sprint(err))
+					return
+				}
+				defer f.Close()
+				r = f
+			}
+			result := ecalc.LoadData(r, name)
+			if result.Error != nil {
+				c.Println(formatResult(result))
+				return
+			}
+			c.Printf("%v => %d values\n", result.Name, len(result.Answer.(esolver.Vector)))
+			c.SetPrompt(prompt(ecalc))
+		},
+	})
 	shell.AddCmd(&ishell.Cmd{
 		Name: "update",
-		Help: "Update ecalc to the latest version",
+		Help: commandHelp("update"),
 		Func: update,
 	})
 }
+
+// solveExpression converts the arguments of the solve command to a call of
+// the solve function, the arguments may be enclosed in parentheses
+func solveExpression(args string) string {
+	args = strings.TrimSpace(args)
+	if strings.HasPrefix(args, "(") {
+		depth := 0
+		for i, ch := range args {
+			if ch == '(' {
+				depth++
+			} else if ch == ')' {
+				depth--
+			}
+			if depth == 0 {
+				if i == len(args)-1 {
+					return "solve" + args
+				}
+				break
+			}
+		}
+	}
+	return "solve(" + args + ")"
+}
+
+// parseTableLoad parses the arguments of "table load <file> [as <name>]
+// [mode]", the name defaults to the file name without extension
+func parseTableLoad(args []string) (path, name string, mode esolver.Interpolation, err error) {
+	const usage = "Usage: table load <file.csv> [as <name>] [linear|nearest|step]"
+	if len(args) < 2 || args[0] != "load" {
+		return "", "", mode, errors.New(usage)
+	}
+	path, args = args[1], args[2:]
+	name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if len(args) >= 2 && args[0] == "as" {
+		name, args = args[1], args[2:]
+	}
+	switch len(args) {
+	case 0:
+	case 1:
+		if mode, err = esolver.ParseInterpolation(args[0]); err != nil {
+			return "", "", mode, err
+		}
+	default:
+		return "", "", mode, errors.New(usage)
+	}
+	if !reValidSetName.MatchString(name) {
+		return "", "", mode, fmt.Errorf("Invalid table name '%v'. Must be just letters", name)
+	}
+	return path, name, mode, nil
+}
+
+func tableLine(t *ecalc.Table) string {
+	shape := fmt.Sprintf("%s(%s, x)  %d rows", t.Name, strings.Join(t.Columns, "|"), len(t.Keys))
+	if t.IsGrid() {
+		shape = fmt.Sprintf("%s(x, y)  %dx%d grid", t.Name, len(t.Keys), len(t.ColumnKeys))
+	} else if len(t.Columns) == 1 {
+		shape = fmt.Sprintf("%s(x)  %d rows", t.Name, len(t.Keys))
+	}
+	return fmt.Sprintf("%s  %s  %s", shape, t.Mode, t.Path)
+}
+
+// parseDataArgs parses the arguments of "data [file] [as <name>]", the
+// name defaults to data
+func parseDataArgs(args []string) (path, name string, err error) {
+	name = "data"
+	if len(args) > 0 && args[0] != "as" {
+		path, args = args[0], args[1:]
+	}
+	if len(args) == 2 && args[0] == "as" {
+		return path, args[1], nil
+	}
+	if len(args) != 0 {
+		return "", "", errors.New("Usage: data [file] [as <name>]")
+	}
+	return path, name, nil
+}
+
+// setDMSFormat reads the arguments of the dms command, the decimals of the
+// seconds and symbols for 45°30′15″ or letters for 45d30'15"
+func setDMSFormat(e *ecalc.ECalc, args []string) error {
+	precision, symbols := e.DMSPrecision, e.DMSSymbols
+	for _, arg := range args {
+		switch arg {
+		case "symbols":
+			symbols = true
+		case "letters":
+			symbols = false
+		default:
+			decimals, err := strconv.Atoi(arg)
+			if err != nil || decimals < 0 || decimals > maxDMSPrecision {
+				return fmt.Errorf("Usage: dms [0-%d] [symbols|letters]", maxDMSPrecision)
+			}
+			precision = decimals
+		}
+	}
+	e.DMSPrecision, e.DMSSymbols = precision, symbols
+	return nil
+}
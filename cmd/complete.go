@@ -0,0 +1,271 @@
+package main
+
+import (
+	"cmp"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/rodcorsi/ecalc"
+	"github.com/rodcorsi/ecalc/esolver"
+)
+
+// completer completes the commands, their arguments, the file paths and
+// the names of the functions, the constants and the variables of the
+// expressions with TAB
+type completer struct {
+	e   *ecalc.ECalc
+	cfg *config
+}
+
+var switchWords = []string{"on", "off"}
+
+// commandArgs lists the commands with the words completed after them, args
+// are the words typed before the word completed
+var commandArgs = map[string]func(c *completer, args []string, word string) []string{
+	"help":    (*completer).helpArgs,
+	"apropos": nil,
+	"exit":    nil,
+	"clear":   nil,
+	"cls":     nil,
+	"hms":     nil,
+	"set":     nil,
+	"cp":      nil,
+	"polar":   nil,
+	"rect":    nil,
+	// solve is completed like an expression
+	"solve":   nil,
+	"tables":  nil,
+	"update":  nil,
+	"info":    words(switchWords...),
+	"show":    words(switchWords...),
+	"money":   words(switchWords...),
+	"complex": words(switchWords...),
+	"dms":     words("symbols", "letters"),
+	"prompt":  words("default"),
+	"angle":   words(esolver.Degrees.String(), esolver.Radians.String()),
+	"strictness": words(
+		esolver.Legacy.String(), esolver.ImplicitMultiplication.String(), esolver.Strict.String(),
+	),
+	"config": (*completer).configArgs,
+	"table":  (*completer).tableArgs,
+	"data":   (*completer).dataArgs,
+}
+
+// words completes the first argument with the words
+func words(w ...string) func(c *completer, args []string, word string) []string {
+	return func(c *completer, args []string, word string) []string {
+		if len(args) > 0 {
+			return nil
+		}
+		return w
+	}
+}
+
+// Do returns the endings of the candidates of the word before the cursor and
+// its length
+func (c *completer) Do(line []rune, pos int) ([][]rune, int) {
+	text := string(line[:pos])
+	fields := strings.Fields(text)
+	if len(fields) == 0 || unicode.IsSpace(line[pos-1]) {
+		fields = append(fields, "")
+	}
+	word := fields[len(fields)-1]
+
+	name := strings.ToLower(fields[0])
+	args, command := commandArgs[name]
+	switch {
+	case len(fields) == 1 && isName(word):
+		return c.candidates(word, append(c.commands(word), c.names(word)...))
+	case command && name != "solve":
+		if args == nil {
+			return nil, 0
+		}
+		var candidates []string
+		for _, w := range args(c, fields[1:len(fields)-1], word) {
+			if strings.HasPrefix(w, word) {
+				candidates = append(candidates, w)
+			}
+		}
+		return c.candidates(word, candidates)
+	}
+	return c.expression(text)
+}
+
+// candidates returns the endings of the candidates after the word
+func (c *completer) candidates(word string, candidates []string) ([][]rune, int) {
+	slices.Sort(candidates)
+	candidates = slices.Compact(candidates)
+	if len(candidates) == 1 && candidates[0] == word && word != "" {
+		// the word is complete, the next one follows a space
+		return [][]rune{[]rune(" ")}, len([]rune(word))
+	}
+	endings := make([][]rune, len(candidates))
+	for i, s := range candidates {
+		endings[i] = []rune(strings.TrimPrefix(s, word))
+	}
+	return endings, len([]rune(word))
+}
+
+// commands returns the commands starting with the word followed by a space
+func (c *completer) commands(word string) []string {
+	var candidates []string
+	for name := range commandArgs {
+		if strings.HasPrefix(name, word) {
+			candidates = append(candidates, name+" ")
+		}
+	}
+	return candidates
+}
+
+// names returns the functions followed by ( and the constants and the
+// variables starting with the word
+func (c *completer) names(word string) []string {
+	var candidates []string
+	for name, t := range c.e.Names() {
+		if !strings.HasPrefix(name, word) {
+			continue
+		}
+		switch t {
+		case esolver.FUNCTION:
+			candidates = append(candidates, name+"(")
+		case esolver.CONSTANT:
+			candidates = append(candidates, name)
+		}
+	}
+	return candidates
+}
+
+// expression completes the name before the cursor, after the parenthesis
+// of a function its signature and its description are listed, the
+// description starts with -- so they are not joined into the line
+func (c *completer) expression(text string) ([][]rune, int) {
+	runes := []rune(text)
+	start := len(runes)
+	for start > 0 && unicode.IsLetter(runes[start-1]) {
+		start--
+	}
+	word := string(runes[start:])
+	if word != "" {
+		return c.candidates(word, c.names(word))
+	}
+	if doc, ok := c.signature(text); ok {
+		return [][]rune{[]rune(doc.Signature), []rune("-- " + doc.Description)}, 0
+	}
+	return nil, 0
+}
+
+// signature returns the documentation of the function whose parenthesis is
+// just before the end of the text
+func (c *completer) signature(text string) (esolver.Doc, bool) {
+	name, ok := strings.CutSuffix(text, "(")
+	if !ok {
+		return esolver.Doc{}, false
+	}
+	runes := []rune(name)
+	start := len(runes)
+	for start > 0 && unicode.IsLetter(runes[start-1]) {
+		start--
+	}
+	name = string(runes[start:])
+	if doc, ok := esolver.Describe(name); ok {
+		return doc, true
+	}
+	for _, t := range c.e.Tables() {
+		if t.Name == name {
+			return esolver.Doc{Signature: name + "(column, key)", Description: "lookup of the table " + t.Path}, true
+		}
+	}
+	return esolver.Doc{}, false
+}
+
+// helpArgs completes the topics and the names of help
+func (c *completer) helpArgs(args []string, word string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+	names := slices.Clone(helpTopics)
+	for _, d := range allDocs() {
+		names = append(names, d.Name)
+	}
+	return names
+}
+
+// configArgs completes config show and config set <key>
+func (c *completer) configArgs(args []string, word string) []string {
+	switch len(args) {
+	case 0:
+		return []string{"show", "set"}
+	case 1:
+		if args[0] != "set" {
+			return nil
+		}
+		keys := slices.Collect(maps.Keys(c.cfg.fields()))
+		for name := range c.cfg.Constants {
+			keys = append(keys, "constants."+name)
+		}
+		return keys
+	}
+	return nil
+}
+
+// tableArgs completes table load <file.csv> as <name> [linear|nearest|step]
+func (c *completer) tableArgs(args []string, word string) []string {
+	switch len(args) {
+	case 0:
+		return []string{"load"}
+	case 1:
+		return filePaths(word)
+	case 2:
+		return []string{"as"}
+	case 4:
+		return []string{esolver.Linear.String(), esolver.Nearest.String(), esolver.Step.String()}
+	}
+	return nil
+}
+
+// dataArgs completes data [file] [as name]
+func (c *completer) dataArgs(args []string, word string) []string {
+	switch {
+	case len(args) == 0:
+		return append(filePaths(word), "as")
+	case len(args) == 1 && args[0] != "as":
+		return []string{"as"}
+	}
+	return nil
+}
+
+// isName tells whether the word may be the start of a command or a name
+func isName(word string) bool {
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// filePaths returns the paths of the entries of the directory of the path
+// starting with its name, the directories end with /
+func filePaths(path string) []string {
+	dir, name := filepath.Split(path)
+	entries, err := os.ReadDir(cmp.Or(expandHome(dir), "."))
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), name) || strings.HasPrefix(entry.Name(), ".") && !strings.HasPrefix(name, ".") {
+			continue
+		}
+		p := dir + entry.Name()
+		if entry.IsDir() {
+			p += "/"
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/abiosoft/ishell"
+	"github.com/fatih/color"
+	"github.com/rodcorsi/ecalc"
+	"github.com/rodcorsi/ecalc/esolver"
+)
+
+// config is read at startup from ~/.config/ecalc/config.toml, the config
+// command changes it and writes it back
+type config struct {
+	// Angle is the unit of the trigonometric functions, degrees or radians
+	Angle string `toml:"angle"`
+	// Strictness is legacy, implicit or strict
+	Strictness   string `toml:"strictness"`
+	Complex      bool   `toml:"complex"`
+	Polar        bool   `toml:"polar"`
+	Money        bool   `toml:"money"`
+	Info         bool   `toml:"info"`
+	DMSPrecision int    `toml:"dms_precision"`
+	DMSSymbols   bool   `toml:"dms_symbols"`
+	Prompt       string `toml:"prompt"`
+	// Clipboard copies the result with CTRL+C, otherwise it exits
+	Clipboard bool `toml:"clipboard"`
+	// Highlight colors the input while typing
+	Highlight bool `toml:"highlight"`
+	// Preview shows the result of the input while typing
+	Preview bool          `toml:"preview"`
+	Colors  colorConfig   `toml:"colors"`
+	History historyConfig `toml:"history"`
+	// Functions are defined at startup by their definitions like
+	// f(x) = x^2, after the constants and the tables
+	Functions []string `toml:"functions,omitempty"`
+	// Constants are defined at startup by their expressions
+	Constants map[string]string `toml:"constants,omitempty"`
+	// Tables are loaded at startup, they define lookup functions
+	Tables []tableConfig `toml:"tables,omitempty"`
+}
+
+// colorConfig holds the colors as words like "cyan", "hi-red" or
+// "bold yellow"
+type colorConfig struct {
+	Prompt   string `toml:"prompt"`
+	Result   string `toml:"result"`
+	Error    string `toml:"error"`
+	Function string `toml:"function"`
+	Warning  string `toml:"warning"`
+	Number   string `toml:"number"`
+	Constant string `toml:"constant"`
+	Preview  string `toml:"preview"`
+}
+
+type historyConfig struct {
+	// File is the history file, empty disables the history
+	File string `toml:"file"`
+	// Size is the number of lines kept
+	Size int `toml:"size"`
+}
+
+type tableConfig struct {
+	Name string `toml:"name"`
+	Path string `toml:"path"`
+	Mode string `toml:"mode,omitempty"`
+}
+
+func defaultConfig() *config {
+	return &config{
+		Angle:        esolver.Degrees.String(),
+		Strictness:   esolver.Legacy.String(),
+		DMSPrecision: 5,
+		Prompt:       defaultPrompt,
+		Clipboard:    true,
+		Highlight:    true,
+		Colors: colorConfig{
+			Prompt:   "cyan",
+			Result:   "yellow",
+			Error:    "red",
+			Function: "yellow",
+			Warning:  "magenta",
+			Number:   "blue",
+			Constant: "green",
+			Preview:  "faint",
+		},
+		History: historyConfig{File: "~/.ecalc_history", Size: 500},
+	}
+}
+
+// configPath returns the config file in the user config directory,
+// ~/.config/ecalc/config.toml on Linux
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ecalc", "config.toml"), nil
+}
+
+// loadConfig reads the config file over the defaults, a missing file
+// keeps the defaults
+func loadConfig(path string) (*config, error) {
+	cfg := defaultConfig()
+	md, err := toml.DecodeFile(path, cfg)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("%s: unknown key %s", path, undecoded[0])
+	}
+	return cfg, nil
+}
+
+// startupConfig reads the config file and applies it, the defaults are
+// used when it is wrong. The path is empty when the file was not loaded, so
+// the defaults are never saved over it
+func startupConfig(e *ecalc.ECalc) (*config, string, error) {
+	path, err := configPath()
+	if err != nil {
+		return defaultConfig(), "", err
+	}
+	cfg, err := loadConfig(path)
+	if err == nil {
+		err = cfg.apply(e)
+	}
+	if err != nil {
+		return defaultConfig(), "", fmt.Errorf("%w, the defaults are used and the changes are not saved", err)
+	}
+	return cfg, path, cfg.loadStartup(e)
+}
+
+// save writes the config file creating its directory
+func (cfg *config) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := cfg.write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (cfg *config) write(w io.Writer) error {
+	enc := toml.NewEncoder(w)
+	enc.Indent = ""
+	return enc.Encode(cfg)
+}
+
+// apply sets the modes, the display formats and the colors of the config,
+// nothing is set when a value is wrong
+func (cfg *config) apply(e *ecalc.ECalc) error {
+	setters := cfg.setters(e)
+	keys := slices.Sorted(maps.Keys(setters))
+	sets := make([]func(), len(keys))
+	for i, key := range keys {
+		var err error
+		if sets[i], err = setters[key](); err != nil {
+			return err
+		}
+	}
+	for _, set := range sets {
+		set()
+	}
+	return nil
+}
+
+// setters maps the keys of the settings applied to the calculator and the
+// display to functions checking the value, they return the function
+// setting it
+func (cfg *config) setters(e *ecalc.ECalc) map[string]func() (func(), error) {
+	always := func(set func()) func() (func(), error) {
+		return func() (func(), error) { return set, nil }
+	}
+	colorSetter := func(name string, c **color.Color) func() (func(), error) {
+		return func() (func(), error) {
+			parsed, err := parseColor(name)
+			if err != nil {
+				return nil, err
+			}
+			return func() { *c = parsed }, nil
+		}
+	}
+	return map[string]func() (func(), error){
+		"angle": func() (func(), error) {
+			angle, err := esolver.ParseAngleUnit(cfg.Angle)
+			if err != nil {
+				return nil, err
+			}
+			return func() { e.SetAngleUnit(angle) }, nil
+		},
+		"strictness": func() (func(), error) {
+			strictness, err := esolver.ParseStrictness(cfg.Strictness)
+			if err != nil {
+				return nil, err
+			}
+			return func() { e.SetStrictness(strictness) }, nil
+		},
+		"prompt": func() (func(), error) {
+			if err := checkPrompt(cfg.Prompt); err != nil {
+				return nil, err
+			}
+			return func() { promptTemplate = cfg.Prompt }, nil
+		},
+		"dms_precision": func() (func(), error) {
+			if cfg.DMSPrecision < 0 || cfg.DMSPrecision > maxDMSPrecision {
+				return nil, fmt.Errorf("dms_precision must be between 0 and %d", maxDMSPrecision)
+			}
+			return func() { e.DMSPrecision = cfg.DMSPrecision }, nil
+		},
+		"complex":         always(func() { e.SetComplexMode(cfg.Complex) }),
+		"polar":           always(func() { e.Polar = cfg.Polar }),
+		"money":           always(func() { e.Money = cfg.Money }),
+		"info":            always(func() { e.Info = cfg.Info }),
+		"dms_symbols":     always(func() { e.DMSSymbols = cfg.DMSSymbols }),
+		"colors.prompt":   colorSetter(cfg.Colors.Prompt, &fmtPrompt),
+		"colors.result":   colorSetter(cfg.Colors.Result, &fmtResult),
+		"colors.error":    colorSetter(cfg.Colors.Error, &fmtError),
+		"colors.function": colorSetter(cfg.Colors.Function, &fmtFunction),
+		"colors.warning":  colorSetter(cfg.Colors.Warning, &fmtWarning),
+		"colors.number":   colorSetter(cfg.Colors.Number, &fmtNumber),
+		"colors.constant": colorSetter(cfg.Colors.Constant, &fmtConstant),
+		"colors.preview":  colorSetter(cfg.Colors.Preview, &fmtPreview),
+	}
+}
+
+// loadStartup defines the constants, loads the tables and defines the
+// functions of the config
+func (cfg *config) loadStartup(e *ecalc.ECalc) error {
+	names := make([]string, 0, len(cfg.Constants))
+	for name := range cfg.Constants {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if err := defineConstant(e, name, cfg.Constants[name]); err != nil {
+			return err
+		}
+	}
+	for _, t := range cfg.Tables {
+		mode, err := esolver.ParseInterpolation(cmp.Or(t.Mode, esolver.Linear.String()))
+		if err != nil {
+			return err
+		}
+		if _, err := e.LoadTable(expandHome(t.Path), t.Name, mode); err != nil {
+			return fmt.Errorf("table %s: %w", t.Name, err)
+		}
+	}
+	for _, def := range cfg.Functions {
+		if err := e.DefineFunction(def); err != nil {
+			return fmt.Errorf("function %s: %w", strings.TrimSpace(def), err)
+		}
+	}
+	return nil
+}
+
+// defineConstant assigns the expression to the name keeping the last
+// answer
+func defineConstant(e *ecalc.ECalc, name, expr string) error {
+	if !reValidSetName.MatchString(name) {
+		return fmt.Errorf("constant %s: the name must be just letters", name)
+	}
+	result, last := e.Result, e.LastAnswer
+	r := e.Eval(name + " = " + expr)
+	e.Result, e.LastAnswer = result, last
+	if r.Error != nil {
+		return fmt.Errorf("constant %s: %w", name, r.Error)
+	}
+	return nil
+}
+
+// fields maps the keys of the settings to the fields of the config
+func (cfg *config) fields() map[string]any {
+	return map[string]any{
+		"angle":           &cfg.Angle,
+		"strictness":      &cfg.Strictness,
+		"complex":         &cfg.Complex,
+		"polar":           &cfg.Polar,
+		"money":           &cfg.Money,
+		"info":            &cfg.Info,
+		"dms_precision":   &cfg.DMSPrecision,
+		"dms_symbols":     &cfg.DMSSymbols,
+		"prompt":          &cfg.Prompt,
+		"clipboard":       &cfg.Clipboard,
+		"highlight":       &cfg.Highlight,
+		"preview":         &cfg.Preview,
+		"colors.prompt":   &cfg.Colors.Prompt,
+		"colors.result":   &cfg.Colors.Result,
+		"colors.error":    &cfg.Colors.Error,
+		"colors.function": &cfg.Colors.Function,
+		"colors.warning":  &cfg.Colors.Warning,
+		"colors.number":   &cfg.Colors.Number,
+		"colors.constant": &cfg.Colors.Constant,
+		"colors.preview":  &cfg.Colors.Preview,
+		"history.file":    &cfg.History.File,
+		"history.size":    &cfg.History.Size,
+	}
+}
+
+// set changes the setting named like dms_precision, colors.prompt or
+// constants.g to the value
+func (cfg *config) set(key, value string) error {
+	if name, ok := strings.CutPrefix(key, "constants."); ok {
+		if cfg.Constants == nil {
+			cfg.Constants = make(map[string]string)
+		}
+		cfg.Constants[name] = value
+		return nil
+	}
+	switch field := cfg.fields()[key].(type) {
+	case *string:
+		*field = value
+	case *bool:
+		b, err := parseSwitch(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		*field = b
+	case *int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be a number", key)
+		}
+		*field = n
+	default:
+		return fmt.Errorf("unknown key %q", key)
+	}
+	return nil
+}
+
+// parseSwitch reads on, off, true or false
+func parseSwitch(s string) (bool, error) {
+	switch s {
+	case "on", "true":
+		return true, nil
+	case "off", "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("%q must be on or off", s)
+}
+
+var colorAttributes = map[string]color.Attribute{
+	"bold": color.Bold, "faint": color.Faint, "italic": color.Italic, "underline": color.Underline,
+	"black": color.FgBlack, "red": color.FgRed, "green": color.FgGreen, "yellow": color.FgYellow,
+	"blue": color.FgBlue, "magenta": color.FgMagenta, "cyan": color.FgCyan, "white": color.FgWhite,
+	"hi-black": color.FgHiBlack, "hi-red": color.FgHiRed, "hi-green": color.FgHiGreen,
+	"hi-yellow": color.FgHiYellow, "hi-blue": color.FgHiBlue, "hi-magenta": color.FgHiMagenta,
+	"hi-cyan": color.FgHiCyan, "hi-white": color.FgHiWhite,
+}
+
+// parseColor returns the color of words like "bold hi-cyan", an empty text
+// is the terminal color
+func parseColor(s string) (*color.Color, error) {
+	var attrs []color.Attribute
+	for _, word := range strings.Fields(s) {
+		attr, ok := colorAttributes[word]
+		if !ok {
+			return nil, fmt.Errorf("unknown color %q", word)
+		}
+		attrs = append(attrs, attr)
+	}
+	return color.New(attrs...), nil
+}
+
+// expandHome replaces the ~ at the start of the path by the home directory
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~")
+	if !ok || (rest != "" && rest[0] != '/' && rest[0] != filepath.Separator) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
+
+// updateConfig changes a setting and applies it, the config is kept when
+// the value is wrong. Only the setting is applied, so the modes changed by
+// commands like angle or polar are kept
+func updateConfig(e *ecalc.ECalc, cfg *config, key, value string) error {
+	next := *cfg
+	next.Constants = maps.Clone(cfg.Constants)
+	if err := next.set(key, value); err != nil {
+		return err
+	}
+	if setter, ok := next.setters(e)[key]; ok {
+		set, err := setter()
+		if err != nil {
+			return err
+		}
+		set()
+	}
+	if name, ok := strings.CutPrefix(key, "constants."); ok {
+		if err := defineConstant(e, name, value); err != nil {
+			return err
+		}
+	}
+	*cfg = next
+	return nil
+}
+
+// configCommand runs "config show" and "config set <key> <value>" saving
+// the change to the config file
+func configCommand(c *ishell.Context, e *ecalc.ECalc, cfg *config, path string) error {
+	const usage = "Usage: config show | config set <key> <value>"
+	args := c.Args
+	switch {
+	case len(args) == 0 || len(args) == 1 && args[0] == "show":
+		var sb strings.Builder
+		if err := cfg.write(&sb); err != nil {
+			return err
+		}
+		c.Printf("# %s\n%s", cmp.Or(path, "the config file was not loaded"), sb.String())
+		return nil
+	case len(args) >= 3 && args[0] == "set":
+		if err := updateConfig(e, cfg, args[1], strings.Join(args[2:], " ")); err != nil {
+			return err
+		}
+		c.SetPrompt(prompt(e))
+		if path == "" {
+			return errors.New("the change is only for this session, the config file was not loaded")
+		}
+		return cfg.save(path)
+	}
+	return errors.New(usage)
+}
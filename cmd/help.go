@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/rodcorsi/ecalc"
+	"github.com/rodcorsi/ecalc/esolver"
+)
+
+// commandsTopic is the topic of the docs of the commands
+const commandsTopic = "Commands"
+
+// command documents a command named by the first word of its usage
+func command(usage, description string, examples ...string) esolver.Doc {
+	name, _, _ := strings.Cut(usage, " ")
+	return esolver.Doc{Name: name, Topic: commandsTopic, Signature: usage, Description: description, Examples: examples}
+}
+
+// commandDocs are in the order of the help and the README
+var commandDocs = []esolver.Doc{
+	command("help [name|functions|constants|operators|commands]", "show the help of a name or a topic",
+		"help sqrt", "help functions"),
+	command("apropos <word>", "search the functions, constants, operators and commands", "apropos interest"),
+	command("exit", "terminate this"),
+	command("dms [decimals] [symbols|letters]", "print last result to Degree Minutes Seconds", "dms 2 symbols"),
+	command("hms", "print last result to Hours Minutes Seconds"),
+	command("info [on|off]", "print last result as decimal, fraction, dms, scientific, hex..., on prints them after each result",
+		"info", "info on"),
+	command("show [on|off]", "same as info command"),
+	command("prompt [template|default]", "set the prompt template with {ans} {ans:eng} {mode} {strictness} {index}",
+		`prompt "[{index}] {mode} {ans:eng} » "`, "prompt default"),
+	command("angle [degrees|radians]", "unit of the angles of the trigonometric functions", "angle radians"),
+	command("strictness [legacy|implicit|strict]", "legacy inserts * and ans, implicit inserts only * like 2pi, strict neither",
+		"strictness strict"),
+	command("config [show|set <key> <value>]", "show the settings or change and save one", "config set dms_precision 2"),
+	command("clear", "clear all screen"),
+	command("cls", "same as clear command"),
+	command("set [name]", "define variable with last value, x by default", "set sigma"),
+	command("cp", "copy to clipboard"),
+	command("update", "update ecalc to the latest version"),
+	command("solve <equation>, [x], [guess]", "find a root of an equation", "solve x^3 - 2x - 5 = 0, x, 2"),
+	command("complex [on|off]", "enable/disable complex results like sqrt(-4)", "complex on"),
+	command("polar", "show complex results as magnitude∠angle"),
+	command("rect", "show complex results as real + imaginary"),
+	command("money [on|off]", "show results with two decimals and thousands separators", "money on"),
+	command("table load <file> as <name> [linear|nearest|step]", "load a CSV lookup table",
+		"table load steel.csv as steel"),
+	command("tables", "list the loaded tables"),
+	command("data [file] [as name]", "read numbers from a file or typed lines into a list", "data loads.txt as loads"),
+}
+
+// commandHelp returns the description of a command
+func commandHelp(name string) string {
+	for _, d := range commandDocs {
+		if d.Name == name {
+			return d.Description
+		}
+	}
+	return ""
+}
+
+// allDocs returns the docs of the solver followed by the ones of the
+// commands
+func allDocs() []esolver.Doc {
+	return append(esolver.Docs(), commandDocs...)
+}
+
+// helpTopics are the words of help that list a kind of docs
+var helpTopics = []string{"functions", "constants", "operators", "commands"}
+
+// topicDocs returns the docs listed by help functions, help constants, help
+// operators or help commands
+func topicDocs(topic string) []esolver.Doc {
+	var docs []esolver.Doc
+	for _, d := range allDocs() {
+		var in bool
+		switch topic {
+		case "constants":
+			in = d.Topic == esolver.ConstantsTopic
+		case "operators":
+			in = d.Topic == esolver.OperatorsTopic
+		case "commands":
+			in = d.Topic == commandsTopic
+		case "functions":
+			in = d.Topic != esolver.ConstantsTopic && d.Topic != esolver.OperatorsTopic && d.Topic != commandsTopic
+		}
+		if in {
+			docs = append(docs, d)
+		}
+	}
+	return docs
+}
+
+// helpText is the text of help without arguments, the names are listed by
+// their topics
+func helpText() string {
+	var sb strings.Builder
+	sb.WriteString(`Ecalc - Engineer command calculator
+Expressions example:
+    5+2
+    15*pi
+    tan45
+    (4+5)*cos45d25m33.15s
+    *2
+    /3
+CTRL+C:
+    Copy result to clipboard
+TAB:
+    complete commands, files, functions, constants and variables, after pv( list its arguments
+Highlighting:
+    the input is colored while typing, config set preview on shows its result before Enter
+Commands:
+`)
+	for _, d := range topicDocs("commands") {
+		fmt.Fprintf(&sb, "    %-10s %s\n", d.Name, d.Description)
+	}
+	topic := ""
+	for _, d := range esolver.Docs() {
+		if d.Topic != topic {
+			if topic != "" {
+				sb.WriteString("\n")
+			}
+			topic = d.Topic
+			fmt.Fprintf(&sb, "%s:\n   ", topic)
+		}
+		sb.WriteString(" " + d.Name)
+	}
+	sb.WriteString(`
+Variables:
+    sigma = 150  check = sigma < 0.6*fy  if(check, sigma, 0)
+ANS:
+    you can use an special variable 'ans' to use the last result on your expression
+More:
+    help <name> shows the signature, the domain and examples of a function, constant, operator
+    or command, help functions|constants|operators|commands lists them, apropos <word> searches
+`)
+	return sb.String()
+}
+
+// docLines writes a line with the signature and the description of each doc
+func docLines(docs []esolver.Doc) string {
+	width := 0
+	for _, d := range docs {
+		width = max(width, len([]rune(d.Signature)))
+	}
+	var sb strings.Builder
+	topic := ""
+	for _, d := range docs {
+		if d.Topic != topic {
+			topic = d.Topic
+			fmt.Fprintf(&sb, "%s:\n", topic)
+		}
+		fmt.Fprintf(&sb, "    %s%s  %s\n", d.Signature, strings.Repeat(" ", width-len([]rune(d.Signature))), d.Description)
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// docText writes the signature, the description, the domain and the
+// examples of a doc, the examples of the expressions are previewed with
+// their results
+func docText(d esolver.Doc, e *ecalc.ECalc) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s\n    %s\n", fmtFunction.Sprint(d.Signature), d.Description)
+	if d.Domain != "" {
+		fmt.Fprintf(&sb, "Domain:\n    %s\n", d.Domain)
+	}
+	if len(d.Examples) > 0 {
+		sb.WriteString("Examples:\n")
+	}
+	for _, example := range d.Examples {
+		if d.Topic == commandsTopic {
+			fmt.Fprintf(&sb, "    %s\n", example)
+			continue
+		}
+		fmt.Fprintf(&sb, "    %s = %s\n", example, formatResult(e.Preview(example)))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// help returns the text of help with its arguments
+func help(e *ecalc.ECalc, args []string) (string, error) {
+	if len(args) == 0 {
+		return helpText(), nil
+	}
+	name := strings.ToLower(strings.Join(args, " "))
+	if slices.Contains(helpTopics, name) {
+		return docLines(topicDocs(name)), nil
+	}
+	var texts []string
+	for _, d := range allDocs() {
+		if d.Name == name {
+			texts = append(texts, docText(d, e))
+		}
+	}
+	if len(texts) > 0 {
+		// solve is a function and a command
+		return strings.Join(texts, "\n\n"), nil
+	}
+	return "", fmt.Errorf("no help for %q, try apropos %s", name, name)
+}
+
+// apropos lists the docs matching the word
+func apropos(word string) (string, error) {
+	var docs []esolver.Doc
+	for _, d := range allDocs() {
+		if d.Matches(word) {
+			docs = append(docs, d)
+		}
+	}
+	if len(docs) == 0 {
+		return "", fmt.Errorf("nothing about %q", word)
+	}
+	return docLines(docs), nil
+}
+
+//go:generate go test -run Test_generateReadme -update
+
+// readmeSections returns the tables of the README generated from the docs by
+// the name of their section
+func readmeSections() map[string]string {
+	code := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return "`" + strings.ReplaceAll(s, "|", `\|`) + "`"
+	}
+	// the descriptions are plain text, not markdown
+	text := strings.NewReplacer("|", `\|`, "*", `\*`, "_", `\_`).Replace
+	example := func(d esolver.Doc) string {
+		if len(d.Examples) == 0 {
+			return ""
+		}
+		return code(d.Examples[0])
+	}
+	table := func(header string, docs []esolver.Doc, row func(d esolver.Doc) []string) string {
+		var sb strings.Builder
+		columns := strings.Split(header, "|")
+		fmt.Fprintf(&sb, "| %s |\n|%s\n", strings.Join(columns, " | "), strings.Repeat(" --- |", len(columns)))
+		for _, d := range docs {
+			fmt.Fprintf(&sb, "| %s |\n", strings.Join(row(d), " | "))
+		}
+		return sb.String()
+	}
+	return map[string]string{
+		"commands": table("Command|Description|Example", topicDocs("commands"), func(d esolver.Doc) []string {
+			return []string{code(d.Signature), text(d.Description), example(d)}
+		}),
+		"operators": table("Operator|Description|Example", topicDocs("operators"), func(d esolver.Doc) []string {
+			return []string{code(d.Signature), text(d.Description), example(d)}
+		}),
+		"functions": table("Topic|Function|Description|Domain|Example", topicDocs("functions"), func(d esolver.Doc) []string {
+			return []string{d.Topic, code(d.Signature), text(d.Description), text(d.Domain), example(d)}
+		}),
+		"constants": table("Constant|Description|Example", topicDocs("constants"), func(d esolver.Doc) []string {
+			return []string{code(d.Name), text(d.Description), example(d)}
+		}),
+	}
+}
+
+// reReadmeSection finds the generated sections of the README between
+// <!-- generated: name --> and <!-- end -->
+var reReadmeSection = regexp.MustCompile(`(?s)(<!-- generated: (\w+) -->\n).*?(<!-- end -->)`)
+
+// generateReadme replaces the generated sections of the README
+func generateReadme(readme string) string {
+	sections := readmeSections()
+	return reReadmeSection.ReplaceAllStringFunc(readme, func(s string) string {
+		m := reReadmeSection.FindStringSubmatch(s)
+		return m[1] + sections[m[2]] + m[3]
+	})
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/abiosoft/readline"
+	"github.com/fatih/color"
+	"github.com/rodcorsi/ecalc"
+	"github.com/rodcorsi/ecalc/esolver"
+)
+
+// fmtMatch marks the parenthesis at the cursor and the one matching it
+var fmtMatch = color.New(color.Bold, color.Underline)
+
+// reEscape finds the color sequences of the prompt
+var reEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// painter colors the expression being typed and shows its result after it
+// without evaluating it into the history, the commands are not colored
+type painter struct {
+	e   *ecalc.ECalc
+	cfg *config
+}
+
+func (p *painter) Paint(line []rune, pos int) []rune {
+	text := string(line)
+	if fields := strings.Fields(text); len(fields) == 0 {
+		return line
+	} else if _, ok := commandArgs[strings.ToLower(fields[0])]; ok {
+		return line
+	}
+	painted := []rune(text)
+	if p.cfg.Highlight {
+		painted = []rune(highlight(p.e.Spans(text), line, pos))
+	}
+	if p.cfg.Preview {
+		if preview := p.preview(text); preview != "" {
+			// the cursor is saved and restored around the preview, so
+			// readline still moves it from the end of the line
+			painted = append(painted, []rune("\x1b7"+fmtPreview.Sprint(preview)+"\x1b8")...)
+		}
+	}
+	return painted
+}
+
+// preview returns the result of the expression like "  = 15", it is empty
+// for the errors, the evaluations stopped by the deadline of the preview,
+// the definitions of functions, the results written in many lines, the
+// results equal to the expression and when it does not fit the screen
+func (p *painter) preview(text string) string {
+	r := p.e.Preview(text)
+	if r.Error != nil || r.Function != "" {
+		return ""
+	}
+	value := r.String()
+	if strings.Contains(value, "\n") || value == strings.TrimSpace(text) {
+		return ""
+	}
+	preview := "  = " + value
+	promptWidth := utf8.RuneCountInString(reEscape.ReplaceAllString(prompt(p.e), ""))
+	if promptWidth+utf8.RuneCountInString(text+preview) >= readline.GetScreenWidth() {
+		return ""
+	}
+	return preview
+}
+
+// highlight colors the numbers, the functions, the constants and the errors
+// of the line, the parentheses without a match are errors and the one at the
+// cursor is marked with its match
+func highlight(spans []esolver.Span, line []rune, pos int) string {
+	colors := make([]*color.Color, len(line))
+	paint := func(start, end int, c *color.Color) {
+		for i := start; i < min(end, len(line)); i++ {
+			colors[i] = c
+		}
+	}
+	var open []esolver.Span
+	match := make(map[int]int)
+	for _, s := range spans {
+		switch s.Type {
+		case esolver.NUMBER:
+			paint(s.Start, s.End, fmtNumber)
+		case esolver.FUNCTION:
+			paint(s.Start, s.End, fmtFunction)
+		case esolver.CONSTANT:
+			paint(s.Start, s.End, fmtConstant)
+		case esolver.ERROR:
+			paint(s.Start, s.End, fmtError)
+		case esolver.LPAREN, esolver.LBRACKET:
+			open = append(open, s)
+		case esolver.RPAREN, esolver.RBRACKET:
+			if len(open) == 0 || pairs[open[len(open)-1].Value] != s.Value {
+				paint(s.Start, s.End, fmtError)
+				continue
+			}
+			o := open[len(open)-1]
+			open = open[:len(open)-1]
+			match[o.Start], match[s.Start] = s.Start, o.Start
+		}
+	}
+	for _, at := range []int{pos, pos - 1} {
+		if other, ok := match[at]; ok {
+			paint(at, at+1, fmtMatch)
+			paint(other, other+1, fmtMatch)
+			break
+		}
+	}
+
+	var sb strings.Builder
+	for start := 0; start < len(line); {
+		end := start + 1
+		for end < len(line) && colors[end] == colors[start] {
+			end++
+		}
+		if c := colors[start]; c != nil {
+			sb.WriteString(c.Sprint(string(line[start:end])))
+		} else {
+			sb.WriteString(string(line[start:end]))
+		}
+		start = end
+	}
+	return sb.String()
+}
+
+// pairs are the closing parentheses and brackets of the opening ones
+var pairs = map[string]string{"(": ")", "[": "]", "{": "}"}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/abiosoft/ishell"
+	"github.com/abiosoft/readline"
 	"github.com/atotto/clipboard"
 	"github.com/fatih/color"
 	"github.com/rodcorsi/ecalc"
@@ -18,25 +19,46 @@ var (
 	fmtResult   = color.New(color.FgYellow)
 	fmtError    = color.New(color.FgRed)
 	fmtFunction = color.New(color.FgYellow)
+	fmtWarning  = color.New(color.FgMagenta)
+	fmtNumber   = color.New(color.FgBlue)
+	fmtConstant = color.New(color.FgGreen)
+	fmtPreview  = color.New(color.Faint)
 )
 
 func main() {
-	shell := ishell.New()
 	ecalc := ecalc.NewECalc()
+	cfg, path, err := startupConfig(ecalc)
+	if err != nil {
+		fmt.Println(fmtError.Sprint("config: ", err))
+	}
 
-	addCommands(shell, ecalc)
+	shell := ishell.NewWithConfig(&readline.Config{
+		HistoryLimit: cfg.History.Size,
+		Painter:      &painter{e: ecalc, cfg: cfg},
+	})
+	addCommands(shell, ecalc, cfg, path)
+	shell.CustomCompleter(&completer{e: ecalc, cfg: cfg})
 
 	shell.NotFound(func(c *ishell.Context) {
 		result := ecalc.Eval(strings.Join(c.Args, " "))
 		c.Println(resultLine(result))
-		c.SetPrompt(prompt(ecalc.LastAnswer))
+		for _, warning := range result.Warnings {
+			c.Println(fmtWarning.Sprint("Warning: ", warning))
+		}
+		if ecalc.Info {
+			if lines := representationLines(result); lines != "" {
+				c.Println(lines)
+			}
+		}
+		history++
+		c.SetPrompt(prompt(ecalc))
 	})
 
-	shell.SetPrompt(prompt(ecalc.Result))
+	shell.SetPrompt(prompt(ecalc))
 	shell.Println(hello)
 	shell.Interrupt(func(c *ishell.Context, count int, input string) {
 		if count == 1 {
-			if clipboard.Unsupported {
+			if clipboard.Unsupported || !cfg.Clipboard {
 				os.Exit(0)
 			} else {
 				value, err := copyToClipboard(c, ecalc)
@@ -51,12 +73,29 @@ func main() {
 		os.Exit(0)
 	})
 	shell.IgnoreCase(true)
-	shell.SetHomeHistoryPath(".ecalc_history")
+	shell.SetHistoryPath(expandHome(cfg.History.File))
 	shell.Run()
 }
 
-func prompt(result *ecalc.Result) string {
-	return fmt.Sprintf("(ans:%v) » ", fmtPrompt.Sprintf("%-10s", formatValue(result.Value)))
+func formatAnswer(result *ecalc.Result) string {
+	switch v := result.Answer.(type) {
+	case *esolver.Complex:
+		if v.Im.Sign() < 0 {
+			return formatValue(v.Re) + "-" + formatValue(new(big.Float).Abs(v.Im)) + "i"
+		}
+		return formatValue(v.Re) + "+" + formatValue(v.Im) + "i"
+	case esolver.Vector:
+		return fmt.Sprintf("vec[%d]", len(v))
+	case esolver.Matrix:
+		return fmt.Sprintf("mat[%dx%d]", len(v), len(v[0]))
+	case esolver.Node:
+		return "expr"
+	case *esolver.Bool:
+		return v.String()
+	case *esolver.Date:
+		return v.Format("2006-01-02")
+	}
+	return formatValue(result.Value)
 }
 
 func formatValue(value *big.Float) string {
@@ -107,7 +146,29 @@ func resultLine(result *ecalc.Result) string {
 			sb.WriteString(value)
 		}
 	})
-	return fmt.Sprintf("%v = %v", sb.String(), formatResult(result))
+	if result.Function != "" && result.Error == nil {
+		// the definition of a function has no value
+		return sb.String()
+	}
+	value := formatResult(result)
+	if strings.Contains(value, "\n") {
+		// matrices start in a new line to keep the columns aligned
+		return fmt.Sprintf("%v =\n%v", sb.String(), value)
+	}
+	return fmt.Sprintf("%v = %v", sb.String(), value)
+}
+
+// representationLines writes a line for each representation of a real
+// result, like "fraction     1/3"
+func representationLines(result *ecalc.Result) string {
+	representations := result.Representations()
+	var lines []string
+	for _, name := range ecalc.RepresentationNames {
+		if value, ok := representations[name]; ok {
+			lines = append(lines, fmt.Sprintf("  %-12s %s", name, fmtResult.Sprint(value)))
+		}
+	}
+	return strings.Join(lines, "\n")
 }
 
 func formatResult(c *ecalc.Result) string {
@@ -118,6 +179,9 @@ func formatResult(c *ecalc.Result) string {
 }
 
 func copyToClipboard(c *ishell.Context, ecalc *ecalc.ECalc) (string, error) {
-	value := ecalc.Result.Value.Text('f', -1)
+	value := ecalc.Result.String()
+	if ecalc.Result.Value != nil {
+		value = ecalc.Result.Value.Text('f', -1)
+	}
 	return value, clipboard.WriteAll(value)
 }
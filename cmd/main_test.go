@@ -1,8 +1,24 @@
 package main
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"flag"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/fatih/color"
+	"github.com/rodcorsi/ecalc"
+	"github.com/rodcorsi/ecalc/esolver"
 )
 
 func Test_formatValue(t *testing.T) {
@@ -29,3 +45,486 @@ func Test_formatValue(t *testing.T) {
 		})
 	}
 }
+
+func Test_solveExpression(t *testing.T) {
+	tests := []struct {
+		args string
+		want string
+	}{
+		{"x^2 = 2", "solve(x^2 = 2)"},
+		{" x^3 - 2x - 5 = 0, x, 2 ", "solve(x^3 - 2x - 5 = 0, x, 2)"},
+		{"(x^2 = 2, x, 1)", "solve(x^2 = 2, x, 1)"},
+		{"(x+1)^2 = 4, x", "solve((x+1)^2 = 4, x)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			if got := solveExpression(tt.args); got != tt.want {
+				t.Errorf("solveExpression() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseTableLoad(t *testing.T) {
+	tests := []struct {
+		args    string
+		path    string
+		name    string
+		mode    esolver.Interpolation
+		wantErr bool
+	}{
+		{"load steel.csv as steel", "steel.csv", "steel", esolver.Linear, false},
+		{"load data/bolts.csv", "data/bolts.csv", "bolts", esolver.Linear, false},
+		{"load steel.csv as s step", "steel.csv", "s", esolver.Step, false},
+		{"load steel.csv nearest", "steel.csv", "steel", esolver.Nearest, false},
+		{"load steel.csv as s cubic", "", "", esolver.Linear, true},
+		{"load steel.csv as s1", "", "", esolver.Linear, true},
+		{"load", "", "", esolver.Linear, true},
+		{"show steel.csv", "", "", esolver.Linear, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			path, name, mode, err := parseTableLoad(strings.Fields(tt.args))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTableLoad() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if path != tt.path || name != tt.name || mode != tt.mode {
+				t.Errorf("parseTableLoad() = %v, %v, %v, want %v, %v, %v", path, name, mode, tt.path, tt.name, tt.mode)
+			}
+		})
+	}
+}
+
+func Test_parseDataArgs(t *testing.T) {
+	tests := []struct {
+		args    string
+		path    string
+		name    string
+		wantErr bool
+	}{
+		{"", "", "data", false},
+		{"loads.txt", "loads.txt", "data", false},
+		{"loads.txt as loads", "loads.txt", "loads", false},
+		{"as loads", "", "loads", false},
+		{"loads.txt as", "", "", true},
+		{"a.txt b.txt", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			path, name, err := parseDataArgs(strings.Fields(tt.args))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDataArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if path != tt.path || name != tt.name {
+				t.Errorf("parseDataArgs() = %v, %v, want %v, %v", path, name, tt.path, tt.name)
+			}
+		})
+	}
+}
+
+func Test_setDMSFormat(t *testing.T) {
+	tests := []struct {
+		args      string
+		precision int
+		symbols   bool
+		wantErr   bool
+	}{
+		{"", 5, false, false},
+		{"2", 2, false, false},
+		{"symbols", 5, true, false},
+		{"0 symbols", 0, true, false},
+		{"letters 3", 3, false, false},
+		{"13", 5, false, true},
+		{"-1", 5, false, true},
+		{"unicode", 5, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			e := ecalc.NewECalc()
+			err := setDMSFormat(e, strings.Fields(tt.args))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("setDMSFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if e.DMSPrecision != tt.precision || e.DMSSymbols != tt.symbols {
+				t.Errorf("setDMSFormat() = %v, %v, want %v, %v", e.DMSPrecision, e.DMSSymbols, tt.precision, tt.symbols)
+			}
+		})
+	}
+}
+
+func Test_loadConfig(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := loadConfig(filepath.Join(dir, "missing.toml"))
+	if err != nil || !reflect.DeepEqual(cfg, defaultConfig()) {
+		t.Errorf("loadConfig() of a missing file = %+v, %v, want the defaults", cfg, err)
+	}
+
+	path := filepath.Join(dir, "config.toml")
+	text := "angle = \"radians\"\ndms_precision = 2\nfunctions = [\"hyp(a, b) = sqrt(a^2 + b^2)\"]\n[colors]\nresult = \"bold hi-green\"\n[constants]\ng = \"9.80665\"\n"
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Angle != "radians" || cfg.DMSPrecision != 2 || cfg.Colors.Result != "bold hi-green" ||
+		cfg.Colors.Error != "red" || cfg.Constants["g"] != "9.80665" || !cfg.Clipboard {
+		t.Errorf("loadConfig() = %+v", cfg)
+	}
+
+	e := ecalc.NewECalc()
+	if err := cfg.apply(e); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.loadStartup(e); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.Eval("sin(pi/2) * g").String(); got != "9.80665" {
+		t.Errorf("sin(pi/2) * g = %v, want 9.80665", got)
+	}
+	if got := e.Eval("hyp(3, 4)").String(); got != "5" {
+		t.Errorf("hyp(3, 4) = %v, want 5", got)
+	}
+
+	if err := os.WriteFile(path, []byte("colour = \"red\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfig(path); err == nil {
+		t.Error("loadConfig() accepted an unknown key")
+	}
+}
+
+func Test_updateConfig(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{"dms_precision", "2", false},
+		{"money", "on", false},
+		{"colors.prompt", "hi-cyan underline", false},
+		{"constants.rho", "7850", false},
+		{"dms_precision", "20", true},
+		{"money", "yes", true},
+		{"angle", "grad", true},
+		{"colors.error", "pink", true},
+		{"constants.bad", "nothing + 1", true},
+		{"history", "x", true},
+	}
+	e := ecalc.NewECalc()
+	cfg := defaultConfig()
+	for _, tt := range tests {
+		t.Run(tt.key+" "+tt.value, func(t *testing.T) {
+			before := *cfg
+			err := updateConfig(e, cfg, tt.key, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("updateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && (cfg.DMSPrecision != before.DMSPrecision || cfg.Money != before.Money || len(cfg.Constants) != len(before.Constants)) {
+				t.Errorf("updateConfig() changed the config on error: %+v", cfg)
+			}
+		})
+	}
+	if e.DMSPrecision != 2 || !e.Money || cfg.Colors.Prompt != "hi-cyan underline" {
+		t.Errorf("updateConfig() did not apply the settings: %+v", cfg)
+	}
+	if got := e.Eval("rho").String(); got != "7,850.00" {
+		t.Errorf("rho = %v, want 7,850.00", got)
+	}
+
+	// the modes changed by the commands are kept
+	e.SetAngleUnit(esolver.Radians)
+	e.Polar = true
+	if err := updateConfig(e, cfg, "dms_symbols", "on"); err != nil {
+		t.Fatal(err)
+	}
+	if e.AngleUnit() != esolver.Radians || !e.Polar || !e.Money || !e.DMSSymbols {
+		t.Errorf("updateConfig() reverted the modes: angle %v, polar %v, money %v", e.AngleUnit(), e.Polar, e.Money)
+	}
+}
+
+func Test_startupConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	path := filepath.Join(dir, "ecalc", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	text := "angle = \"grad\"\nmoney = true\n"
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := ecalc.NewECalc()
+	cfg, got, err := startupConfig(e)
+	if err == nil || got != "" || !reflect.DeepEqual(cfg, defaultConfig()) {
+		t.Fatalf("startupConfig() = %+v, %q, %v, want the defaults, no path and an error", cfg, got, err)
+	}
+	if e.Money {
+		t.Error("startupConfig() applied a wrong config")
+	}
+
+	if err := os.WriteFile(path, []byte("money = true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, got, err := startupConfig(e); err != nil || got != path || !e.Money {
+		t.Errorf("startupConfig() = %q, %v, want %q applying money", got, err, path)
+	}
+}
+
+func Test_renderPrompt(t *testing.T) {
+	e := ecalc.NewECalc()
+	e.Eval("15000")
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{defaultPrompt, "(ans:15000     ) » "},
+		{"[{index}] {mode} {ans:eng} » ", "[3] deg 15e+03 » "},
+		{"{ans:hex} {ans:frac} {strictness}> ", "0x3a98 15000      legacy> "},
+		{"{ans:sci}", "1.5e+04"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			if err := checkPrompt(tt.template); err != nil {
+				t.Fatal(err)
+			}
+			if got := renderPrompt(tt.template, e, 3); got != tt.want {
+				t.Errorf("renderPrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	for _, template := range []string{"{units}", "{ans:roman}", "{mode:rad}"} {
+		if err := checkPrompt(template); err == nil {
+			t.Errorf("checkPrompt(%q) accepted the template", template)
+		}
+	}
+}
+
+func Test_completer(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "steel.csv"), []byte("x,fy\n10,250\n20,300\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sections"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	e := ecalc.NewECalc()
+	e.Eval("sigma = 150")
+	c := &completer{e: e, cfg: defaultConfig()}
+	tests := []struct {
+		line   string
+		want   []string
+		length int
+	}{
+		{"sqrtp", []string{"hi", "i"}, 5},
+		{"2*sig", []string{"ma"}, 3},
+		{"1 + atan", []string{"("}, 4},
+		{"tab", []string{"le ", "les "}, 3},
+		{"strictness ", []string{"implicit", "legacy", "strict"}, 0},
+		{"angle rad", []string{"ians"}, 3},
+		{"angle radians", []string{" "}, 7},
+		{"config set colors.w", []string{"arning"}, 8},
+		{"table load " + dir + "/s", []string{"ections/", "teel.csv"}, len(dir) + 2},
+		{"table load steel.csv as steel n", []string{"earest"}, 1},
+		{"solve x^2 - 2 = 0, x, sq", []string{"rt(", "rte", "rtii", "rtphi", "rtpi"}, 2},
+		{"pv(", []string{"pv(rate, nper, pmt, fv, type)", "-- present value"}, 0},
+		{"cls ", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, length := c.Do([]rune(tt.line), len([]rune(tt.line)))
+			var endings []string
+			for _, g := range got {
+				endings = append(endings, string(g))
+			}
+			if !reflect.DeepEqual(endings, tt.want) || length != tt.length {
+				t.Errorf("Do() = %q, %v, want %q, %v", endings, length, tt.want, tt.length)
+			}
+		})
+	}
+}
+
+func Test_highlight(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = false
+	e := ecalc.NewECalc()
+	tests := []struct {
+		line string
+		pos  int
+		want string
+	}{
+		{"2*sqrt(pi)", 0, fmtNumber.Sprint("2") + "*" + fmtFunction.Sprint("sqrt") + "(" + fmtConstant.Sprint("pi") + ")"},
+		{"(1+y)", 5, fmtMatch.Sprint("(") + fmtNumber.Sprint("1") + "+" + fmtError.Sprint("y") + fmtMatch.Sprint(")")},
+		{"[1)]", 1, fmtMatch.Sprint("[") + fmtNumber.Sprint("1") + fmtError.Sprint(")") + fmtMatch.Sprint("]")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			line := []rune(tt.line)
+			if got := highlight(e.Spans(tt.line), line, tt.pos); got != tt.want {
+				t.Errorf("highlight() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+var updateReadme = flag.Bool("update", false, "rewrite the generated sections of the README")
+
+// Test_generateReadme checks that the tables of the README are the ones of
+// the docs, go test ./cmd -run Test_generateReadme -update rewrites them
+func Test_generateReadme(t *testing.T) {
+	const path = "../README.md"
+	readme, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := generateReadme(string(readme))
+	if *updateReadme {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	if got != string(readme) {
+		t.Error("the README is out of date, run go test ./cmd -run Test_generateReadme -update")
+	}
+	for name := range readmeSections() {
+		if !strings.Contains(got, "<!-- generated: "+name+" -->") {
+			t.Errorf("the README has no %s section", name)
+		}
+	}
+}
+
+func Test_help(t *testing.T) {
+	e := ecalc.NewECalc()
+	tests := []struct {
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{nil, []string{"apropos", "Statistics:\n    sum mean median", "Constants:\n    e pi phi"}, false},
+		{[]string{"sqrt"}, []string{"sqrt(x)", "square root", "Domain:\n    x >= 0", "sqrt(2) = 1.41421356237"}, false},
+		{[]string{"solve"}, []string{"solve(equation, x, guess)", "solve <equation>, [x], [guess]"}, false},
+		{[]string{"operators"}, []string{"Operators:\n    a + b", "not p"}, false},
+		{[]string{"commands"}, []string{"Commands:\n    help [name", "tables"}, false},
+		{[]string{"nothing"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			got, err := help(e, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("help() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("help() = %q, want %q in it", got, want)
+				}
+			}
+		})
+	}
+
+	got, err := apropos("INTEREST")
+	if err != nil || !strings.Contains(got, "simpleint(principal, rate, nper)") || strings.Contains(got, "sqrt") {
+		t.Errorf("apropos(INTEREST) = %q, %v", got, err)
+	}
+	if _, err := apropos("zzz"); err == nil {
+		t.Error("apropos(zzz) found something")
+	}
+	for name := range commandArgs {
+		if commandHelp(name) == "" {
+			t.Errorf("the command %s has no doc", name)
+		}
+	}
+}
+
+func Test_updater(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin := []byte("new ecalc")
+	sum := sha256.Sum256(bin)
+	manifest := []byte(hex.EncodeToString(sum[:]) + "  ecalc\n" + strings.Repeat("0", 64) + "  ecalc.exe\n")
+
+	tests := []struct {
+		name    string
+		files   map[string][]byte
+		key     ed25519.PublicKey
+		binary  string
+		wantErr string
+	}{
+		{"verified", nil, public, "ecalc", ""},
+		{"tampered binary", map[string][]byte{"ecalc": []byte("evil ecalc")}, public, "ecalc", "checksum does not match"},
+		{"wrong checksum", nil, public, "ecalc.exe", "checksum does not match"},
+		{"no checksum", nil, public, "ecalc32.exe", "has no checksum"},
+		{"tampered manifest", map[string][]byte{manifestName: append([]byte("x"), manifest...)}, public, "ecalc", "signature does not match"},
+		{"other key", nil, ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)).Public().(ed25519.PublicKey), "ecalc", "signature does not match"},
+		{"no signature", map[string][]byte{manifestName + ".sig": nil}, public, "ecalc", "404"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string][]byte{
+				manifestName:          manifest,
+				manifestName + ".sig": ed25519.Sign(private, manifest),
+				"ecalc":               bin,
+				"ecalc.exe":           bin,
+				"ecalc32.exe":         bin,
+			}
+			for name, data := range tt.files {
+				if data == nil {
+					delete(files, name)
+				} else {
+					files[name] = data
+				}
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, ok := files[strings.TrimPrefix(r.URL.Path, "/latest/")]
+				if !ok {
+					http.NotFound(w, r)
+					return
+				}
+				w.Write(data)
+			}))
+			defer server.Close()
+
+			u, err := newUpdater(server.URL+"/latest", base64.StdEncoding.EncodeToString(tt.key))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := u.fetch(tt.binary)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("fetch() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !bytes.Equal(got, bin) {
+				t.Fatalf("fetch() = %q, %v", got, err)
+			}
+		})
+	}
+
+	for _, key := range []string{"", "bm90IGEga2V5"} {
+		if _, err := newUpdater("http://localhost", key); err == nil {
+			t.Errorf("newUpdater(%q) accepted the key", key)
+		}
+	}
+}
+
+func Test_replaceExecutable(t *testing.T) {
+	exePath := filepath.Join(t.TempDir(), "ecalc")
+	if err := os.WriteFile(exePath, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	oldExePath, err := replaceExecutable(exePath, []byte("new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(exePath); string(data) != "new" {
+		t.Errorf("the executable is %q, want new", data)
+	}
+	if data, _ := os.ReadFile(oldExePath); string(data) != "old" {
+		t.Errorf("the old executable is %q, want old", data)
+	}
+}
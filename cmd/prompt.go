@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rodcorsi/ecalc"
+)
+
+// defaultPrompt shows the last answer like (ans:7         ) »
+const defaultPrompt = "(ans:{ans}) » "
+
+// promptTemplate is the prompt, its placeholders are replaced by
+// renderPrompt
+var promptTemplate = defaultPrompt
+
+// history counts the expressions evaluated, the prompt shows the index of
+// the next one
+var history int
+
+// rePlaceholder finds the placeholders like {ans} or {ans:eng}
+var rePlaceholder = regexp.MustCompile(`\{([a-z]+)(?::([a-z]+))?\}`)
+
+// ansFormats are the short names of the representations of {ans:format}
+var ansFormats = map[string]string{
+	"dec": "decimal", "frac": "fraction", "sci": "scientific", "eng": "engineering",
+}
+
+// checkPrompt reports the unknown placeholders of a prompt template
+func checkPrompt(template string) error {
+	for _, m := range rePlaceholder.FindAllStringSubmatch(template, -1) {
+		name, format := m[1], m[2]
+		switch {
+		case name == "ans":
+			if format != "" && ansFormat(format) == "" {
+				return fmt.Errorf("unknown format %q of {ans}, use %s", format, strings.Join(ecalc.RepresentationNames, ", "))
+			}
+		case format != "":
+			return fmt.Errorf("{%s} has no format", name)
+		case name != "mode" && name != "strictness" && name != "index":
+			return fmt.Errorf("unknown placeholder {%s}, use {ans}, {ans:eng}, {mode}, {strictness} or {index}", name)
+		}
+	}
+	return nil
+}
+
+// ansFormat returns the representation named by a format of {ans:format}
+func ansFormat(format string) string {
+	if name, ok := ansFormats[format]; ok {
+		return name
+	}
+	for _, name := range ecalc.RepresentationNames {
+		if name == format {
+			return name
+		}
+	}
+	return ""
+}
+
+// prompt renders the prompt template with the last answer
+func prompt(e *ecalc.ECalc) string {
+	return renderPrompt(promptTemplate, e, history+1)
+}
+
+// renderPrompt replaces the placeholders of the template: {ans} is the last
+// answer in 10 characters and {ans:eng} in a representation like eng, sci,
+// frac or hex, {mode} is the angle unit deg or rad, {strictness} the
+// strictness and {index} the number of the next expression
+func renderPrompt(template string, e *ecalc.ECalc, index int) string {
+	return rePlaceholder.ReplaceAllStringFunc(template, func(s string) string {
+		m := rePlaceholder.FindStringSubmatch(s)
+		switch m[1] {
+		case "ans":
+			answer := fmt.Sprintf("%-10s", formatAnswer(e.LastAnswer))
+			if m[2] != "" {
+				if value, ok := e.LastAnswer.Representations()[ansFormat(m[2])]; ok {
+					answer = value
+				}
+			}
+			return fmtPrompt.Sprint(answer)
+		case "mode":
+			return e.AngleUnit().String()[:3]
+		case "strictness":
+			return e.Strictness().String()
+		case "index":
+			return strconv.Itoa(index)
+		}
+		return s
+	})
+}
@@ -1,92 +1,191 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
 	"runtime"
+	"strings"
 
 	"github.com/abiosoft/ishell"
 )
 
-func update(c *ishell.Context) {
-	c.Println("Starting update...")
+// releaseURL is where the release workflow publishes the binaries, their
+// checksums and the signature of the checksums
+const releaseURL = "https://github.com/rodcorsi/ecalc/releases/download/latest"
+
+// manifestName lists the SHA-256 of the binaries like sha256sum, its
+// ed25519 signature is in manifestName + ".sig"
+const manifestName = "checksums.txt"
+
+// maxDownload limits the size of the files downloaded by update
+const maxDownload = 64 << 20
 
-	var binName string
-	switch runtime.GOOS {
+// releaseKey is the base64 ed25519 public key verifying the releases, the
+// release build sets it with -ldflags "-X main.releaseKey=..."
+var releaseKey string
+
+// updater downloads a release binary verifying the signature of the
+// checksums and the checksum of the binary
+type updater struct {
+	url    string
+	key    ed25519.PublicKey
+	client *http.Client
+}
+
+func newUpdater(url, key string) (*updater, error) {
+	if key == "" {
+		return nil, errors.New("this build has no release key, download the new version manually")
+	}
+	k, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(k) != ed25519.PublicKeySize {
+		return nil, errors.New("the release key is not a base64 ed25519 public key")
+	}
+	return &updater{url: url, key: ed25519.PublicKey(k), client: http.DefaultClient}, nil
+}
+
+// binaryName returns the name of the release binary of the system
+func binaryName(goos, goarch string) (string, error) {
+	switch goos {
 	case "linux":
-		binName = "ecalc"
+		return "ecalc", nil
 	case "windows":
-		if runtime.GOARCH == "386" {
-			binName = "ecalc32.exe"
-		} else {
-			binName = "ecalc.exe"
+		if goarch == "386" {
+			return "ecalc32.exe", nil
 		}
-	default:
-		c.Printf("Update not supported for this OS: %s", runtime.GOOS)
-		return
+		return "ecalc.exe", nil
 	}
+	return "", fmt.Errorf("update not supported for this OS: %s", goos)
+}
 
-	url := "https://github.com/rodcorsi/ecalc/releases/download/latest/" + binName
-	c.Printf("Downloading from %s...", url)
-
-	resp, err := http.Get(url)
+// download returns the file of the release
+func (u *updater) download(name string) ([]byte, error) {
+	resp, err := u.client.Get(u.url + "/" + name)
 	if err != nil {
-		c.Printf("Error downloading update: %v", err)
-		return
+		return nil, err
 	}
 	defer resp.Body.Close()
-
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		c.Printf("Error downloading update: status %s%s", resp.Status, body)
-		return
+		return nil, fmt.Errorf("%s: status %s", name, resp.Status)
 	}
-
-	exePath, err := os.Executable()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownload+1))
 	if err != nil {
-		c.Printf("Error getting executable path: %v", err)
-		return
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if len(data) > maxDownload {
+		return nil, fmt.Errorf("%s: larger than %d bytes", name, maxDownload)
 	}
+	return data, nil
+}
 
-	newExePath := exePath + ".new"
-	newExe, err := os.Create(newExePath)
+// fetch downloads the binary, it fails when the signature of the checksums
+// or the checksum of the binary do not match
+func (u *updater) fetch(name string) ([]byte, error) {
+	manifest, err := u.download(manifestName)
 	if err != nil {
-		c.Printf("Error creating temporary file: %v", err)
-		return
+		return nil, err
 	}
-
-	_, err = io.Copy(newExe, resp.Body)
-	newExe.Close() // Close after writing
+	signature, err := u.download(manifestName + ".sig")
 	if err != nil {
-		c.Printf("Error writing downloaded content: %v", err)
-		os.Remove(newExePath)
-		return
+		return nil, err
+	}
+	if !ed25519.Verify(u.key, manifest, signature) {
+		return nil, fmt.Errorf("%s: the signature does not match the release key", manifestName)
+	}
+	want, err := checksum(manifest, name)
+	if err != nil {
+		return nil, err
+	}
+	bin, err := u.download(name)
+	if err != nil {
+		return nil, err
 	}
+	if got := sha256.Sum256(bin); hex.EncodeToString(got[:]) != want {
+		return nil, fmt.Errorf("%s: the checksum does not match %s", name, manifestName)
+	}
+	return bin, nil
+}
+
+// checksum returns the SHA-256 of the file in the lines "<hex>  <name>" of
+// the manifest
+func checksum(manifest []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(manifest))
+	for scanner.Scan() {
+		sum, file, ok := strings.Cut(scanner.Text(), "  ")
+		// sha256sum marks the files read in binary mode with *
+		if ok && strings.TrimPrefix(file, "*") == name {
+			return strings.ToLower(sum), nil
+		}
+	}
+	return "", fmt.Errorf("%s has no checksum of %s", manifestName, name)
+}
 
+// replaceExecutable writes the binary over the executable keeping it as
+// exePath + ".old"
+func replaceExecutable(exePath string, bin []byte) (oldExePath string, err error) {
+	newExePath := exePath + ".new"
 	// Make the new file executable (not needed on Windows)
-	if runtime.GOOS != "windows" {
-		if err := os.Chmod(newExePath, 0755); err != nil {
-			c.Printf("Warning: could not make the new executable file executable: %v", err)
-		}
+	if err := os.WriteFile(newExePath, bin, 0o755); err != nil {
+		os.Remove(newExePath)
+		return "", fmt.Errorf("writing the new executable: %w", err)
 	}
 
-	oldExePath := exePath + ".old"
+	oldExePath = exePath + ".old"
 	os.Remove(oldExePath) // remove old backup if it exists
 
 	if err := os.Rename(exePath, oldExePath); err != nil {
-		c.Printf("Error moving current executable: %v", err)
 		os.Remove(newExePath)
-		return
+		return "", fmt.Errorf("moving the current executable: %w", err)
 	}
 
 	if err := os.Rename(newExePath, exePath); err != nil {
-		c.Printf("Error replacing executable with new version: %v", err)
 		// Try to restore
 		if err := os.Rename(oldExePath, exePath); err != nil {
-			c.Printf("CRITICAL: Failed to restore old executable. Please do it manually from %s", oldExePath)
+			return "", fmt.Errorf("CRITICAL: failed to restore old executable, please do it manually from %s", oldExePath)
 		}
+		return "", fmt.Errorf("replacing the executable: %w", err)
+	}
+	return oldExePath, nil
+}
+
+func update(c *ishell.Context) {
+	c.Println("Starting update...")
+
+	binName, err := binaryName(runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		c.Println(fmtError.Sprint(err))
+		return
+	}
+	u, err := newUpdater(releaseURL, releaseKey)
+	if err != nil {
+		c.Println(fmtError.Sprint(err))
+		return
+	}
+
+	c.Printf("Downloading and verifying %s/%s...\n", releaseURL, binName)
+	bin, err := u.fetch(binName)
+	if err != nil {
+		c.Println(fmtError.Sprint("Update refused: ", err))
+		return
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		c.Printf("Error getting executable path: %v", err)
+		return
+	}
+	oldExePath, err := replaceExecutable(exePath, bin)
+	if err != nil {
+		c.Println(fmtError.Sprint(err))
 		return
 	}
 
@@ -103,4 +202,4 @@ func update(c *ishell.Context) {
 	}
 
 	c.Stop()
-}
\ No newline at end of file
+}
@@ -0,0 +1,42 @@
+package ecalc
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/rodcorsi/ecalc/esolver"
+)
+
+// LoadData reads a list of numbers into the variable name and makes it the
+// last answer, so mean(ans) works right after loading
+func (e *ECalc) LoadData(r io.Reader, name string) *Result {
+	c := &Result{Expression: name, Name: name}
+	e.Result = c
+
+	stack, err := e.solver.ParseExpression(name)
+	if err != nil {
+		c.Error = err
+		return c
+	}
+	if len(stack.Values) != 1 {
+		c.Error = fmt.Errorf("%q is not a valid name", name)
+		return c
+	}
+	tok := stack.Values[0]
+	if tok.Type != esolver.VARIABLE && (tok.Type != esolver.CONSTANT || !e.variables[tok.Value]) {
+		c.Error = fmt.Errorf("%s is already defined", tok.Value)
+		return c
+	}
+	c.Name = tok.Value
+	c.StackExpr = stack
+
+	data, err := esolver.ReadNumbers(r)
+	if err != nil {
+		c.Error = err
+		return c
+	}
+	c.Answer = data
+	e.AddValue(c.Name, data)
+	e.LastAnswer = c
+	return c
+}
@@ -1,13 +1,17 @@
 package ecalc
 
 import (
+	"fmt"
 	"math/big"
-	"regexp"
+	"time"
 
 	"github.com/rodcorsi/ecalc/esolver"
 )
 
-var reDegree = regexp.MustCompile(`d|'|"|atan|acos|asin`)
+// previewTime limits the evaluation of a preview, it is evaluated while the
+// expression is typed
+const previewTime = 100 * time.Millisecond
+
 var minEngNotation = big.NewFloat(0.00000001)
 var maxEngNotation = big.NewFloat(9999999999999.0)
 
@@ -15,74 +19,250 @@ type ECalc struct {
 	solver     esolver.ESolver
 	Result     *Result
 	LastAnswer *Result
+	// Polar shows complex results as magnitude∠angle
+	Polar bool
+	// DMSPrecision is the number of decimals of the seconds of the angles
+	DMSPrecision int
+	// DMSSymbols shows the angles with the symbols ° ′ ″ instead of d ' "
+	DMSSymbols bool
+	// Money shows real results as 1,234.56
+	Money bool
+	// Info prints every representation of the results after them
+	Info bool
+	// variables defined by the user that may be assigned again
+	variables map[string]bool
+	// functions defined by the user like f(x) = x^2
+	functions map[string]bool
+	tables    map[string]*Table
 }
 
 func NewECalc() *ECalc {
 	e := &ECalc{
-		solver: esolver.New(),
+		solver:       esolver.New(),
+		variables:    make(map[string]bool),
+		functions:    make(map[string]bool),
+		tables:       make(map[string]*Table),
+		DMSPrecision: 5,
 	}
-	e.solver.AddConstant("ans", func() *big.Float {
-		return e.LastAnswer.Value
+	e.solver.AddValue("ans", func() esolver.Value {
+		return e.LastAnswer.Answer
+	})
+	e.solver.SetKind("ans", func() esolver.Kind {
+		return e.LastAnswer.Kind
 	})
 	e.Eval("0")
 	return e
 }
 
 func (e *ECalc) Eval(expr string) *Result {
-	c := &Result{
-		Expression: expr,
-		Degree:     reDegree.MatchString(expr),
+	if reDefinition.MatchString(expr) {
+		// a definition keeps the result and the last answer
+		return e.define(expr, true)
 	}
+	c := e.eval(expr)
 	e.Result = c
+	if c.Error != nil {
+		return c
+	}
+	if c.Name != "" {
+		e.AddResult(c.Name, c)
+	}
+	e.LastAnswer = c
+	return c
+}
+
+// Preview evaluates the expression like Eval without assigning it nor
+// changing the result and the last answer, the evaluations taking more
+// than previewTime stop with esolver.ErrDeadline
+func (e *ECalc) Preview(expr string) *Result {
+	if reDefinition.MatchString(expr) {
+		return e.define(expr, false)
+	}
+	e.solver.SetDeadline(time.Now().Add(previewTime))
+	defer e.solver.SetDeadline(time.Time{})
+	return e.eval(expr)
+}
+
+// Spans returns the tokens of the expression with their positions for the
+// highlighting of the input
+func (e *ECalc) Spans(expr string) []esolver.Span {
+	return e.solver.Spans(expr)
+}
 
-	stack, err := e.solver.ParseExpression(expr)
+func (e *ECalc) eval(expr string) *Result {
+	c := &Result{Expression: expr}
+
+	stack, warnings, err := e.solver.ParseWarnings(expr)
 	if err != nil {
 		c.Error = err
 		return c
 	}
+	c.Warnings = warnings
+
+	name, assign := assignment(stack)
+	if assign {
+		c.Name = name.Value
+		if _, ok := e.tables[name.Value]; ok {
+			c.Error = fmt.Errorf("%s is a table", name.Value)
+			return c
+		}
+		if e.functions[name.Value] {
+			c.Error = fmt.Errorf("%s is a function, define it again like %s(x) = ...", name.Value, name.Value)
+			return c
+		}
+		if name.Type == esolver.FUNCTION {
+			c.Error = fmt.Errorf("%s is a built-in function", name.Value)
+			return c
+		}
+		if name.Type == esolver.CONSTANT && !e.variables[name.Value] {
+			c.Error = fmt.Errorf("%s is a constant", name.Value)
+			return c
+		}
+		stack.Values = stack.Values[2:]
+	}
 
-	stack, c.Partial = addANS(stack)
+	stack, warning, err := addANS(stack, e.solver.Strictness())
+	if err != nil {
+		c.Error = err
+		return c
+	}
+	if warning != "" {
+		c.Partial = true
+		c.Warnings = append(c.Warnings, warning)
+	}
 	c.StackExpr = stack
+	if assign {
+		c.StackExpr.Values = append([]esolver.Token{name, {Type: esolver.OPERATOR, Value: "="}}, stack.Values...)
+	}
 
-	c.Value, c.Error = e.solver.SolveStack(stack)
+	c.Answer, c.Kind, c.Error = e.solver.EvalKind(stack)
 
 	if c.Error != nil {
 		return c
 	}
-	e.LastAnswer = c
+	c.Degree = c.Kind == esolver.Angle
+	c.Time = c.Kind == esolver.Duration
+	c.Polar = e.Polar
+	c.Radians = e.solver.AngleUnit() == esolver.Radians
+	c.Money = e.Money
+	c.DMSPrecision = e.DMSPrecision
+	c.DMSSymbols = e.DMSSymbols
 
-	if c.Value.Cmp(big.NewFloat(0)) == 0 {
-		c.EngNotation = false
+	if c.Value, err = esolver.Real(c.Answer); err != nil {
 		return c
 	}
-	v := new(big.Float).Abs(c.Value)
-	c.EngNotation = (v.Cmp(maxEngNotation) > 0 || v.Cmp(minEngNotation) < 0)
+	c.EngNotation = isEngNotation(c.Value)
 
 	return c
 }
 
 func (e *ECalc) AddConstant(name string, value *big.Float) {
+	e.variables[name] = true
 	e.solver.AddConstant(name, func() *big.Float {
 		return value
 	})
 }
 
-func addANS(stack esolver.Stack) (esolver.Stack, bool) {
-	if len(stack.Values) == 0 {
-		return stack, false
+// AddValue defines a constant with a real or complex value
+func (e *ECalc) AddValue(name string, value esolver.Value) {
+	e.variables[name] = true
+	e.solver.AddValue(name, func() esolver.Value {
+		return value
+	})
+}
+
+// AddResult defines a constant with the answer of a result keeping its kind,
+// so a variable assigned an angle is shown in degrees, minutes and seconds
+func (e *ECalc) AddResult(name string, r *Result) {
+	e.AddValue(name, r.Answer)
+	kind := r.Kind
+	e.solver.SetKind(name, func() esolver.Kind {
+		return kind
+	})
+}
+
+// SetStrictness sets which missing operators are inserted and when ans is
+// used for a missing operand
+func (e *ECalc) SetStrictness(s esolver.Strictness) {
+	e.solver.SetStrictness(s)
+}
+
+func (e *ECalc) Strictness() esolver.Strictness {
+	return e.solver.Strictness()
+}
+
+// SetAngleUnit sets the unit of the angles of the trigonometric functions
+func (e *ECalc) SetAngleUnit(u esolver.AngleUnit) {
+	e.solver.SetAngleUnit(u)
+}
+
+func (e *ECalc) AngleUnit() esolver.AngleUnit {
+	return e.solver.AngleUnit()
+}
+
+// SetComplexMode enables complex results for functions like sqrt(-4)
+func (e *ECalc) SetComplexMode(enabled bool) {
+	e.solver.SetComplexMode(enabled)
+}
+
+func (e *ECalc) ComplexMode() bool {
+	return e.solver.ComplexMode()
+}
+
+// Names returns the functions, the constants and the variables known by the
+// calculator with their token types
+func (e *ECalc) Names() map[string]esolver.TokenType {
+	return e.solver.Names()
+}
+
+func isEngNotation(value *big.Float) bool {
+	if value.Sign() == 0 {
+		return false
 	}
+	v := new(big.Float).Abs(value)
+	return v.Cmp(maxEngNotation) > 0 || v.Cmp(minEngNotation) < 0
+}
 
-	added := false
+// assignment returns the name assigned by an expression like x = 2*y, the
+// name may be a constant or a function that can not be assigned
+func assignment(stack esolver.Stack) (esolver.Token, bool) {
+	if len(stack.Values) < 3 {
+		return esolver.Token{}, false
+	}
+	name, op := stack.Values[0], stack.Values[1]
+	if (name.Type != esolver.VARIABLE && name.Type != esolver.CONSTANT && name.Type != esolver.FUNCTION) || op != (esolver.Token{Type: esolver.OPERATOR, Value: "="}) {
+		return esolver.Token{}, false
+	}
+	return name, true
+}
+
+// addANS uses ans for the missing operand of a partial expression like *2
+// or sin returning a warning, unless the strictness is legacy the missing
+// operand is an error
+func addANS(stack esolver.Stack, strictness esolver.Strictness) (esolver.Stack, string, error) {
+	if len(stack.Values) == 0 {
+		return stack, "", nil
+	}
 
-	if stack.Values[0].Type == esolver.OPERATOR {
+	if v := stack.Values[0]; v.Type == esolver.OPERATOR && v.Value != "not" {
+		if strictness != esolver.Legacy {
+			if v.Value == "-" || v.Value == "+" {
+				// a sign
+				return stack, "", nil
+			}
+			return stack, "", fmt.Errorf("missing operand before %s", v.Value)
+		}
 		// first token is an operator add ans constant first
 		stack.Values = append([]esolver.Token{esolver.Token{Type: esolver.CONSTANT, Value: "ans"}}, stack.Values...)
-		added = true
+		return stack, "ans inserted before " + v.Value, nil
 	} else if v := stack.Values[len(stack.Values)-1]; v.Type == esolver.FUNCTION || v.Type == esolver.OPERATOR {
+		if strictness != esolver.Legacy {
+			return stack, "", fmt.Errorf("missing operand after %s", v.Value)
+		}
 		// last token is an operator or function add ans constant in the last position
 		stack.Push(esolver.Token{Type: esolver.CONSTANT, Value: "ans"})
-		added = true
+		return stack, "ans inserted after " + v.Value, nil
 	}
 
-	return stack, added
+	return stack, "", nil
 }
@@ -0,0 +1,275 @@
+package ecalc
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rodcorsi/ecalc/esolver"
+)
+
+func TestAssignment(t *testing.T) {
+	e := NewECalc()
+	steps := []struct {
+		expr string
+		want string
+		line string
+	}{
+		{"sigma = 150", "150", "sigma = 150"},
+		{"fy = 250", "250", "fy = 250"},
+		{"check = sigma < 0.6*fy", "false (150 < 150)", "check = sigma < 0.6*fy"},
+		{"sigma = 140", "140", "sigma = 140"},
+		{"sigma < 0.6fy", "true (140 < 150)", "sigma < 0.6*fy"},
+		{"check", "false (150 < 150)", "check"},
+		{"fy = fy*2", "500", "fy = fy*2"},
+	}
+	for _, step := range steps {
+		r := e.Eval(step.expr)
+		if got := r.String(); got != step.want {
+			t.Errorf("Eval(%q) = %v, want %v", step.expr, got, step.want)
+		}
+		var sb strings.Builder
+		r.FormatExpression(func(value string, _ esolver.Token) { sb.WriteString(value) })
+		if got := sb.String(); got != step.line {
+			t.Errorf("FormatExpression(%q) = %v, want %v", step.expr, got, step.line)
+		}
+	}
+}
+
+func TestAssignmentErrors(t *testing.T) {
+	e := NewECalc()
+	for _, expr := range []string{"pi = 3", "ans = 2", "x = y + 1"} {
+		if r := e.Eval(expr); r.Error == nil {
+			t.Errorf("Eval(%q) = %v, expected error", expr, r)
+		}
+	}
+	if r := e.Eval("x"); r.Error == nil {
+		t.Errorf("failed assignment defined x = %v", r)
+	}
+	for _, name := range []string{"rate", "pv", "var", "mode", "sum"} {
+		want := name + " is a built-in function"
+		if r := e.Eval(name + " = 1"); r.Error == nil || r.Error.Error() != want {
+			t.Errorf("Eval(%q) error = %v, want %v", name+" = 1", r.Error, want)
+		}
+	}
+}
+
+func TestDefineFunction(t *testing.T) {
+	e := NewECalc()
+	e.Eval("5")
+	for _, def := range []string{"f(x) = x^2", "hyp(a, b) = sqrt(a^2 + b^2)", "g(x) = 2f(x) + e"} {
+		r := e.Eval(def)
+		if r.Error != nil {
+			t.Fatalf("Eval(%q) failed: %v", def, r.Error)
+		}
+		var sb strings.Builder
+		r.FormatExpression(func(value string, _ esolver.Token) { sb.WriteString(value) })
+		if got := sb.String(); got != strings.ReplaceAll(def, "2f", "2*f") {
+			t.Errorf("FormatExpression(%q) = %v", def, got)
+		}
+	}
+	if got := e.Result.String(); got != "5" {
+		t.Errorf("a definition changed the result to %v", got)
+	}
+	for expr, want := range map[string]string{"f(3)": "9", "hyp(3, 4)": "5", "f 3 + 1": "10", "g(1) - e": "2"} {
+		if got := e.Eval(expr).String(); got != want {
+			t.Errorf("Eval(%q) = %v, want %v", expr, got, want)
+		}
+	}
+
+	// in order, k is defined before it is redefined calling itself
+	definitions := []struct {
+		def  string
+		want string
+	}{
+		{"sin(x) = x", "sin is a built-in name"},
+		{"h(x, x) = x", "the parameter x is repeated"},
+		{"h(x) = x + y", `unknown name "y"`},
+		{"h(sum) = sum", "the parameter sum is a function"},
+		{"f(x) = f(x) + 1", ""},
+		{"k(x) = 2x", ""},
+		{"k(x) = k(x - 1)*x", ""},
+	}
+	for _, tt := range definitions {
+		if r := e.Eval(tt.def); (r.Error == nil && tt.want != "") || (r.Error != nil && r.Error.Error() != tt.want) {
+			t.Errorf("Eval(%q) error = %v, want %q", tt.def, r.Error, tt.want)
+		}
+	}
+	if r := e.Eval("f(1)"); r.Error == nil || r.Error.Error() != "f: more than 256 nested calls" {
+		t.Errorf("f(1) of a recursive f = %v", r)
+	}
+	if r := e.Eval("f = 2"); r.Error == nil || r.Error.Error() != "f is a function, define it again like f(x) = ..." {
+		t.Errorf("f = 2 error = %v", r.Error)
+	}
+	if got := e.Eval("x = 2").String(); got != "2" {
+		t.Errorf("x = 2 = %v", got)
+	}
+	if r := e.Preview("p(x) = x"); r.Error != nil || r.Function != "p" {
+		t.Errorf("Preview() = %+v", r)
+	}
+	if r := e.Eval("p(1)"); r.Error == nil {
+		t.Errorf("Preview defined p(1) = %v", r)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	e := NewECalc()
+	e.Eval("5")
+	for expr, want := range map[string]string{"ans*2": "10", "*3": "15", "x = 4": "4"} {
+		if got := e.Preview(expr).String(); got != want {
+			t.Errorf("Preview(%q) = %v, want %v", expr, got, want)
+		}
+	}
+	if got := e.Result.String(); got != "5" || e.LastAnswer != e.Result {
+		t.Errorf("Preview changed the result to %v", got)
+	}
+	if r := e.Eval("x"); r.Error == nil {
+		t.Errorf("Preview assigned x = %v", r)
+	}
+
+	// the slow evaluations stop at the deadline, only the preview has it
+	for _, expr := range []string{"binomcdf(1000000, 1000000, 0.5)", "poissoncdf(1000000, 5)"} {
+		if r := e.Preview(expr); !errors.Is(r.Error, esolver.ErrDeadline) {
+			t.Errorf("Preview(%q) error = %v, want %v", expr, r.Error, esolver.ErrDeadline)
+		}
+	}
+	if got := e.Eval("binomcdf(100, 100, 0.5)").String(); got != "1" {
+		t.Errorf("Eval after a preview = %v, want 1", got)
+	}
+}
+
+func TestDocExamples(t *testing.T) {
+	for _, d := range esolver.Docs() {
+		e := NewECalc()
+		for _, example := range d.Examples {
+			if r := e.Eval(example); r.Error != nil {
+				t.Errorf("%s example %q: %v", d.Name, example, r.Error)
+			}
+		}
+	}
+}
+
+func TestResultKind(t *testing.T) {
+	e := NewECalc()
+	steps := []struct {
+		expr string
+		want string
+	}{
+		{"width = 45.5", "45.5"},
+		{"width*2", "91"},
+		{"a = 45d30'", `45d30'0.00000"`},
+		{"a/2", `22d45'0.00000"`},
+		{"ans + 1", `23d45'0.00000"`},
+		{"30d/15d", "2"},
+		{"2h 30m + 45m", "3h 15m 0s"},
+		{"ans + 45m", "4h 0m 0s"},
+		{"t = 1h 30m", "1h 30m 0s"},
+		{"t + 45m", "2h 15m 0s"},
+		{"start = 2026-10-18", "2026-10-18"},
+		{"start + 90d", "2027-01-16"},
+		{"ans + 1d", "2027-01-17"},
+		{"start - 2*45d", "2026-07-20"},
+	}
+	for _, step := range steps {
+		if got := e.Eval(step.expr).String(); got != step.want {
+			t.Errorf("Eval(%q) = %v, want %v", step.expr, got, step.want)
+		}
+	}
+}
+
+func TestStrictness(t *testing.T) {
+	tests := []struct {
+		strictness esolver.Strictness
+		expr       string
+		want       string
+		warnings   []string
+	}{
+		{esolver.Legacy, "*2", "20", []string{"ans inserted before *"}},
+		{esolver.Legacy, "abs", "10", []string{"ans inserted after abs"}},
+		{esolver.Legacy, "2 5", "10", []string{"* inserted between 2 and 5"}},
+		{esolver.ImplicitMultiplication, "2 5", "10", []string{"* inserted between 2 and 5"}},
+		{esolver.ImplicitMultiplication, "-2", "-2", nil},
+		{esolver.ImplicitMultiplication, "*2", "missing operand before *", nil},
+		{esolver.Strict, "sqrt", "missing operand after sqrt", nil},
+		{esolver.Strict, "2 5", "missing operator between 2 and 5", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strictness.String()+" "+tt.expr, func(t *testing.T) {
+			e := NewECalc()
+			e.SetStrictness(tt.strictness)
+			e.Eval("10")
+			r := e.Eval(tt.expr)
+			if got := r.String(); got != tt.want {
+				t.Errorf("Eval(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+			if !slices.Equal(r.Warnings, tt.warnings) {
+				t.Errorf("Warnings = %q, want %q", r.Warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestLoadTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "steel.csv")
+	csv := "thickness,fy,fu\n16,355,470\n40,345,470\n"
+	if err := os.WriteFile(path, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	e := NewECalc()
+	e.AddValue("steel", big.NewFloat(1))
+	if _, err := e.LoadTable(path, "Steel", esolver.Linear); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.Eval("steel(fy, 28)").String(); got != "350" {
+		t.Errorf("steel(fy, 28) = %v, want 350", got)
+	}
+	if r := e.Eval("steel = 2"); r.Error == nil {
+		t.Error("steel = 2 assigned a table")
+	}
+	if tables := e.Tables(); len(tables) != 1 || tables[0].Name != "steel" || tables[0].Path != path {
+		t.Errorf("Tables() = %v, want the steel table", tables)
+	}
+	if _, err := e.LoadTable(path, "sqrt", esolver.Linear); err == nil {
+		t.Error("LoadTable(sqrt) redefined a built-in function")
+	}
+	if _, err := e.LoadTable(filepath.Join(t.TempDir(), "missing.csv"), "x", esolver.Linear); err == nil {
+		t.Error("LoadTable() of a missing file did not fail")
+	}
+}
+
+func TestLoadData(t *testing.T) {
+	e := NewECalc()
+	r := e.LoadData(strings.NewReader("2 4 4 4\n5 5 7 9\n"), "loads")
+	if r.Error != nil {
+		t.Fatal(r.Error)
+	}
+	if e.LastAnswer != r {
+		t.Error("LoadData() did not set the last answer")
+	}
+	steps := []struct {
+		expr string
+		want string
+	}{
+		{"mean(ans)", "5"},
+		{"*2", "10"},
+		{"max(loads) - min(loads)", "7"},
+		{"median loads", "4.5"},
+	}
+	for _, step := range steps {
+		if got := e.Eval(step.expr).String(); got != step.want {
+			t.Errorf("Eval(%q) = %v, want %v", step.expr, got, step.want)
+		}
+	}
+	for _, name := range []string{"pi", "mean", "2x"} {
+		if r := e.LoadData(strings.NewReader("1"), name); r.Error == nil {
+			t.Errorf("LoadData(%q) did not fail", name)
+		}
+	}
+	if r := e.LoadData(strings.NewReader("1 a"), "x"); r.Error == nil {
+		t.Error("LoadData() of a word did not fail")
+	}
+}
@@ -0,0 +1,57 @@
+package esolver
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// AngleUnit is the unit of the angles of the trigonometric functions and of
+// the polar form of the complex numbers. The bearings are always in degrees
+type AngleUnit int
+
+const (
+	Degrees AngleUnit = iota
+	Radians
+)
+
+var angleUnitNames = []string{"degrees", "radians"}
+
+func (u AngleUnit) String() string {
+	if u < 0 || int(u) >= len(angleUnitNames) {
+		return fmt.Sprintf("AngleUnit(%d)", int(u))
+	}
+	return angleUnitNames[u]
+}
+
+// ParseAngleUnit returns the unit named degrees or radians, deg and rad
+// are accepted too
+func ParseAngleUnit(name string) (AngleUnit, error) {
+	switch name {
+	case "degrees", "deg":
+		return Degrees, nil
+	case "radians", "rad":
+		return Radians, nil
+	}
+	return Degrees, fmt.Errorf("unknown angle unit %q, use degrees or radians", name)
+}
+
+// angleArgument are the functions taking an angle, angleResult the ones
+// returning one
+var angleArgument = map[string]bool{"sin": true, "cos": true, "tan": true}
+
+// applyRadians evaluates the trigonometric functions, that work in degrees,
+// converting the radians of the argument or the result
+func (e *esolver) applyRadians(name string, x Value) (Value, error) {
+	if angleArgument[name] && isNumeric(x) {
+		x, err := e.applyOperator("*", x, big.NewFloat(radToDeg))
+		if err != nil {
+			return nil, err
+		}
+		return e.applyFunc(name, x)
+	}
+	y, err := e.applyFunc(name, x)
+	if err != nil || !angleResult[name] {
+		return y, err
+	}
+	return e.applyOperator("*", y, big.NewFloat(degToRad))
+}
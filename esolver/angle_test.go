@@ -0,0 +1,59 @@
+package esolver
+
+import (
+	"fmt"
+	"testing"
+)
+
+func Test_esolver_Radians(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+		kind Kind
+	}{
+		{"sin(pi/6)", "0.5", Plain},
+		{"cos(pi)", "-1", Plain},
+		{"sin(30d)", "0.5", Plain},
+		{"atan(1)*4", "3.14159265358979", Plain},
+		{"asin(1)", "1.5707963267949", Plain},
+		{"azimuth(-90)", "270", Angle},
+		{"diff(sin(x), x)", "cos(x)", Plain},
+		{"abs(3+4i)∠arg(3+4i)", "3+4i", Plain},
+		{"2∠pi", "-2", Plain},
+		{"10∠30d", "8.660254038+5i", Plain},
+	}
+	e := New()
+	e.SetAngleUnit(Radians)
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			stack, err := e.ParseExpression(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseExpression() failed: %v", err)
+			}
+			got, kind, err := e.EvalKind(stack)
+			if err != nil {
+				t.Fatalf("EvalKind() failed: %v", err)
+			}
+			var s string
+			if x, ok := got.(interface{ Text(byte, int) string }); ok {
+				s = x.Text('g', 15)
+			} else {
+				s = fmt.Sprint(got)
+			}
+			if s != tt.want || kind != tt.kind {
+				t.Errorf("EvalKind() = %v %v, want %v %v", s, kind, tt.want, tt.kind)
+			}
+		})
+	}
+}
+
+func TestParseAngleUnit(t *testing.T) {
+	for name, want := range map[string]AngleUnit{"degrees": Degrees, "deg": Degrees, "radians": Radians, "rad": Radians} {
+		if got, err := ParseAngleUnit(name); err != nil || got != want {
+			t.Errorf("ParseAngleUnit(%q) = %v, %v", name, got, err)
+		}
+	}
+	if _, err := ParseAngleUnit("grad"); err == nil {
+		t.Error("ParseAngleUnit(grad) did not fail")
+	}
+}
@@ -0,0 +1,313 @@
+package esolver
+
+import (
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Node is a node of the expression tree returned by Parse, its String
+// method is the canonical form of the expression with the minimum
+// parentheses, parsing it gives the same tree
+type Node interface {
+	String() string
+	node()
+}
+
+// Number is a numeric literal, Kind is the kind of the numbers written
+// with markers like 45d30' or 2h 30m and Marked is the number with the
+// markers d, m and s which may be degrees or a time, like 90d
+type Number struct {
+	Value  *big.Float
+	Kind   Kind
+	Marked string
+}
+
+// Ident is the name of a constant or a variable
+type Ident struct {
+	Name string
+}
+
+// UnaryOp is a prefix operator like -x
+type UnaryOp struct {
+	Op string
+	X  Node
+}
+
+// BinaryOp is an infix operator like x + y
+type BinaryOp struct {
+	Op string
+	X  Node
+	Y  Node
+}
+
+// Call is the application of a function to its arguments
+type Call struct {
+	Func string
+	Args []Node
+}
+
+// List is a vector like [1, 2] or {1, 2}, the rows of a matrix are lists
+type List struct {
+	Items []Node
+}
+
+func (*Number) node()   {}
+func (*Ident) node()    {}
+func (*UnaryOp) node()  {}
+func (*BinaryOp) node() {}
+func (*Call) node()     {}
+func (*List) node()     {}
+
+func (n *Number) String() string {
+	if n.Marked != "" {
+		return n.Marked
+	}
+	return n.Value.Text('f', -1) + n.Kind.Marker()
+}
+
+func (n *Ident) String() string {
+	return n.Name
+}
+
+func (n *UnaryOp) String() string {
+	x := n.X.String()
+	if nodePrec(n.X) <= nodePrec(n) {
+		x = "(" + x + ")"
+	}
+	if n.Op == "not" {
+		return n.Op + " " + x
+	}
+	return n.Op + x
+}
+
+func (n *BinaryOp) String() string {
+	opr := oprData[n.Op]
+	x := n.X.String()
+	if p := nodePrec(n.X); p < opr.prec || (p == opr.prec && opr.rAsoc) {
+		x = "(" + x + ")"
+	}
+	y := n.Y.String()
+	if p := nodePrec(n.Y); p < opr.prec || (p == opr.prec && !opr.rAsoc && n.Op != "+" && n.Op != "*") {
+		y = "(" + y + ")"
+	}
+	if isSpaced(n.Op) {
+		return x + " " + n.Op + " " + y
+	}
+	return x + n.Op + y
+}
+
+func (n *Call) String() string {
+	return n.Func + "(" + joinNodes(n.Args) + ")"
+}
+
+func (n *List) String() string {
+	return "[" + joinNodes(n.Items) + "]"
+}
+
+func joinNodes(nodes []Node) string {
+	s := make([]string, len(nodes))
+	for i, n := range nodes {
+		s[i] = n.String()
+	}
+	return strings.Join(s, ", ")
+}
+
+// isSpaced reports whether the binary operator is printed between spaces
+func isSpaced(op string) bool {
+	opr, ok := oprData[op]
+	return ok && opr.prec <= oprData["+"].prec
+}
+
+// nodePrec returns the binding power of the node when printed, operands
+// with a lower one are enclosed in parentheses
+func nodePrec(n Node) int {
+	switch x := n.(type) {
+	case *BinaryOp:
+		return oprData[x.Op].prec
+	case *UnaryOp:
+		return precedence(Token{Type: UNARY, Value: x.Op})
+	case *Number:
+		if x.Value.Sign() < 0 {
+			return unaryPrec
+		}
+	}
+	return funcPrec + 1
+}
+
+// Parse parses the expression to a tree, like in the solver missing
+// multiplications are added and functions may be called without parentheses
+func Parse(expr string) (Node, error) {
+	stack, err := New().ParseExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	return buildTree(ShuntingYard(stack).Values)
+}
+
+// Visitor visits the nodes of a tree, when Visit returns a non nil visitor
+// it visits the children of the node
+type Visitor interface {
+	Visit(n Node) Visitor
+}
+
+// Walk visits the tree in depth first order calling v.Visit for each node
+func Walk(v Visitor, n Node) {
+	if v = v.Visit(n); v == nil {
+		return
+	}
+	switch n := n.(type) {
+	case *UnaryOp:
+		Walk(v, n.X)
+	case *BinaryOp:
+		Walk(v, n.X)
+		Walk(v, n.Y)
+	case *Call:
+		for _, arg := range n.Args {
+			Walk(v, arg)
+		}
+	case *List:
+		for _, item := range n.Items {
+			Walk(v, item)
+		}
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(n Node) Visitor {
+	if f(n) {
+		return f
+	}
+	return nil
+}
+
+// Inspect walks the tree calling f for each node, the children are
+// skipped when f returns false
+func Inspect(n Node, f func(Node) bool) {
+	Walk(inspector(f), n)
+}
+
+// Idents returns the names of the constants and the variables referenced
+// by the expression in the order they appear
+func Idents(n Node) []string {
+	var names []string
+	Inspect(n, func(n Node) bool {
+		if id, ok := n.(*Ident); ok && !slices.Contains(names, id.Name) {
+			names = append(names, id.Name)
+		}
+		return true
+	})
+	return names
+}
+
+// Rewrite returns a copy of the tree replacing each node by f(node), the
+// children are rewritten before their parent
+func Rewrite(n Node, f func(Node) Node) Node {
+	switch x := n.(type) {
+	case *UnaryOp:
+		n = &UnaryOp{x.Op, Rewrite(x.X, f)}
+	case *BinaryOp:
+		n = &BinaryOp{x.Op, Rewrite(x.X, f), Rewrite(x.Y, f)}
+	case *Call:
+		n = &Call{x.Func, rewriteNodes(x.Args, f)}
+	case *List:
+		n = &List{rewriteNodes(x.Items, f)}
+	}
+	return f(n)
+}
+
+func rewriteNodes(nodes []Node, f func(Node) Node) []Node {
+	rewritten := make([]Node, len(nodes))
+	for i, n := range nodes {
+		rewritten[i] = Rewrite(n, f)
+	}
+	return rewritten
+}
+
+// buildTree converts the expression in postfix to a tree, the arguments of
+// lazy functions are kept as plain arguments
+func buildTree(tokens []Token) (Node, error) {
+	var stack []Node
+	popArgs := func(n int) ([]Node, error) {
+		if n > len(stack) {
+			return nil, errInvalidExpression
+		}
+		args := append([]Node(nil), stack[len(stack)-n:]...)
+		stack = stack[:len(stack)-n]
+		return args, nil
+	}
+	nArgs := 1
+
+	for _, t := range tokens {
+		switch t.Type {
+		case NUMBER:
+			x, err := parseNumber(t.Value)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, &Number{Value: x, Kind: t.Kind, Marked: t.Marked})
+		case CONSTANT, VARIABLE:
+			stack = append(stack, &Ident{t.Value})
+		case ARGS:
+			var err error
+			if nArgs, err = strconv.Atoi(t.Value); err != nil {
+				return nil, err
+			}
+		case FUNCTION:
+			args, err := popArgs(nArgs)
+			nArgs = 1
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, &Call{t.Value, args})
+		case RBRACKET:
+			items, err := popArgs(nArgs)
+			nArgs = 1
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, &List{items})
+		case UNARY:
+			x, err := popArgs(1)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, &UnaryOp{t.Value, x[0]})
+		case OPERATOR:
+			xy, err := popArgs(2)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, &BinaryOp{t.Value, xy[0], xy[1]})
+		}
+	}
+	if len(stack) != 1 {
+		return nil, errInvalidExpression
+	}
+	return stack[0], nil
+}
+
+// number creates the node of a rational number, fractions are kept as a
+// quotient so they are exact
+func number(r *big.Rat) Node {
+	abs := new(big.Rat).Abs(r)
+	var n Node = &Number{Value: new(big.Float).SetPrec(floatPrec).SetInt(abs.Num())}
+	if !abs.IsInt() {
+		n = &BinaryOp{"/", n, &Number{Value: new(big.Float).SetPrec(floatPrec).SetInt(abs.Denom())}}
+	}
+	if r.Sign() < 0 {
+		return &UnaryOp{"-", n}
+	}
+	return n
+}
+
+// rational returns the exact value of a number as it was written
+func rational(n *Number) *big.Rat {
+	if r, ok := new(big.Rat).SetString(n.Value.Text('g', -1)); ok {
+		return r
+	}
+	r, _ := n.Value.Rat(nil)
+	return r
+}
@@ -0,0 +1,168 @@
+package esolver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"2x^2 + 3x - 1", "2*x^2 + 3*x - 1"},
+		{"5tan45", "5*tan(45)"},
+		{"-2^2", "-2^2"},
+		{"sqrt(a^2 + b^2)", "sqrt(a^2 + b^2)"},
+		{"integrate(x^2, x, 0, 1)", "integrate(x^2, x, 0, 1)"},
+		{"x^2 = 2", "x^2 = 2"},
+		{"3∠45", "3∠45"},
+		{"[1,2]", "[1, 2]"},
+		{"sum({1,2})", "sum([1, 2])"},
+		{"[[1,2],[3,4]]*x", "[[1, 2], [3, 4]]*x"},
+		{"45d30' + 1", "45.5° + 1"},
+		{"2h 30m", "2.5h"},
+		{"2026-10-18 + 90d", "2026-10-18 + 90d"},
+		{"45d 30m", "45d30m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			n, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+			if got := n.String(); got != tt.want {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+			again, err := Parse(n.String())
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", n.String(), err)
+			}
+			if !reflect.DeepEqual(again, n) {
+				t.Errorf("Parse(%q) = %v, want the same tree", n.String(), again)
+			}
+		})
+	}
+}
+
+func TestParseKind(t *testing.T) {
+	tests := []struct {
+		expr string
+		want Kind
+	}{
+		{"45d30", Angle},
+		{"2h", Duration},
+		{"45", Plain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			n, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+			if x, ok := n.(*Number); !ok || x.Kind != tt.want {
+				t.Errorf("Parse() = %#v, want a number of kind %v", n, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Node_String(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"1+2*3", "1 + 2*3"},
+		{"(1+2)*3", "(1 + 2)*3"},
+		{"a-(b-c)", "a - (b - c)"},
+		{"a-(b+c)", "a - (b + c)"},
+		{"(a-b)-c", "a - b - c"},
+		{"a/(b*c)", "a/(b*c)"},
+		{"2^3^2", "2^3^2"},
+		{"(2^3)^2", "(2^3)^2"},
+		{"(-x)^2", "(-x)^2"},
+		{"-x^2", "-x^2"},
+		{"x^(-2)", "x^(-2)"},
+		{"sin(x+1)^2", "sin(x + 1)^2"},
+		{"sin x", "sin(x)"},
+		{"-(-x)", "-(-x)"},
+		{"0.25x", "0.25*x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if got := parseTree(t, tt.expr).String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTree(t *testing.T) {
+	n, err := Parse("-a + f*sin(b)")
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	want := &BinaryOp{"+",
+		&UnaryOp{"-", &Ident{"a"}},
+		&BinaryOp{"*", &Ident{"f"}, &Call{"sin", []Node{&Ident{"b"}}}},
+	}
+	if !reflect.DeepEqual(n, want) {
+		t.Errorf("Parse() = %#v, want %#v", n, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{"1 +", "[1, 2", "1 ** 2"} {
+		if n, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) = %v, expected error", expr, n)
+		}
+	}
+}
+
+type countVisitor map[string]int
+
+func (c countVisitor) Visit(n Node) Visitor {
+	switch n := n.(type) {
+	case *Call:
+		c[n.Func]++
+		// the arguments of the function are not counted
+		return nil
+	case *Ident:
+		c[n.Name]++
+	}
+	return c
+}
+
+func TestWalk(t *testing.T) {
+	n, _ := Parse("x*y + sin(x) + x")
+	got := countVisitor{}
+	Walk(got, n)
+	want := countVisitor{"x": 2, "y": 1, "sin": 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk() = %v, want %v", got, want)
+	}
+}
+
+func TestIdents(t *testing.T) {
+	n, _ := Parse("sigma*l/(e*a) + sigma + pi")
+	want := []string{"sigma", "l", "e", "a", "pi"}
+	if got := Idents(n); !reflect.DeepEqual(got, want) {
+		t.Errorf("Idents() = %v, want %v", got, want)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	n, _ := Parse("x^2 + sin(x)")
+	got := Rewrite(n, func(n Node) Node {
+		if id, ok := n.(*Ident); ok && id.Name == "x" {
+			return &BinaryOp{"+", &Ident{"t"}, &Ident{"h"}}
+		}
+		return n
+	})
+	if want := "(t + h)^2 + sin(t + h)"; got.String() != want {
+		t.Errorf("Rewrite() = %v, want %v", got, want)
+	}
+	if want := "x^2 + sin(x)"; n.String() != want {
+		t.Errorf("Rewrite() changed the tree to %v", n)
+	}
+}
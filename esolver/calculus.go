@@ -0,0 +1,289 @@
+package esolver
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Estimate is the result of integrate and deriv, in any other operation it
+// is the estimated value
+type Estimate struct {
+	X     *big.Float
+	Error *big.Float
+}
+
+func (e *Estimate) String() string {
+	return e.X.String()
+}
+
+const (
+	maxIntervals     = 1000
+	maxRichardsonLen = 10
+)
+
+var (
+	errNoIntegral = errors.New("the integral does not converge")
+	errNotFinite  = errors.New("the function is not finite")
+
+	// integralTolerance is the relative tolerance of the integral
+	integralTolerance = big.NewFloat(1e-14)
+)
+
+// Gauss-Kronrod 15 point nodes and weights, the even nodes are the Gauss 7
+// point nodes
+var (
+	kronrodNodes = parseFloats(
+		"0.991455371120812639206854697526329",
+		"0.949107912342758524526189684047851",
+		"0.864864423359769072789712788640926",
+		"0.741531185599394439863864773280788",
+		"0.586087235467691130294144845693013",
+		"0.405845151377397166906606412076961",
+		"0.207784955007898467600689403773245",
+		"0",
+	)
+	kronrodWeights = parseFloats(
+		"0.022935322010529224963732008058970",
+		"0.063092092629978553290700663189204",
+		"0.104790010322250183839876322541518",
+		"0.140653259715525918745189590510238",
+		"0.169004726639267902826583426598550",
+		"0.190350578064785409913256402421014",
+		"0.204432940075298892414161999234649",
+		"0.209482141084727828012999174891714",
+	)
+	gaussWeights = parseFloats(
+		"0.129484966168869693270611432679082",
+		"0.279705391489276667901467771423780",
+		"0.381830050505118944950369775488975",
+		"0.417959183673469387755102040816327",
+	)
+)
+
+func parseFloats(values ...string) []*big.Float {
+	floats := make([]*big.Float, len(values))
+	for i, v := range values {
+		floats[i], _, _ = big.ParseFloat(v, 10, floatPrec, big.ToNearestEven)
+	}
+	return floats
+}
+
+// bindVariable returns the real function of the variable named by the
+// second argument, the variable shadows the names of the enclosing scope
+// only inside the expression
+func bindVariable(args []Value) (func(x *big.Float) (*big.Float, error), error) {
+	expr := args[0].(*Expr)
+	name, err := variableName(expr, args[1:2])
+	if err != nil {
+		return nil, err
+	}
+	return func(x *big.Float) (*big.Float, error) {
+		v, err := expr.Eval(map[string]Value{name: x})
+		if err != nil {
+			return nil, err
+		}
+		return Real(operand(v))
+	}, nil
+}
+
+// realArgs evaluates the arguments that are real numbers
+func realArgs(args []Value) ([]*big.Float, error) {
+	values := make([]*big.Float, len(args))
+	for i, arg := range args {
+		v, err := arg.(*Expr).Eval(nil)
+		if err != nil {
+			return nil, err
+		}
+		if values[i], err = Real(operand(v)); err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i+3, err)
+		}
+	}
+	return values, nil
+}
+
+// integrate computes the definite integral integrate(x^2, x, 0, 3) with the
+// adaptive Gauss-Kronrod rule, the interval with the largest error is split
+// until the total error is within the tolerance
+func integrate(args []Value) (Value, error) {
+	f, err := bindVariable(args)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := realArgs(args[2:])
+	if err != nil {
+		return nil, err
+	}
+
+	type interval struct {
+		a, b       *big.Float
+		value, err *big.Float
+	}
+	newInterval := func(a, b *big.Float) (*interval, error) {
+		value, err, e := gaussKronrod(f, a, b)
+		return &interval{a, b, value, err}, e
+	}
+
+	first, err := newInterval(limits[0], limits[1])
+	if err != nil {
+		return nil, err
+	}
+	intervals := []*interval{first}
+	for {
+		total := new(big.Float)
+		totalErr := new(big.Float)
+		worst := 0
+		for i, in := range intervals {
+			total.Add(total, in.value)
+			totalErr.Add(totalErr, in.err)
+			if in.err.Cmp(intervals[worst].err) > 0 {
+				worst = i
+			}
+		}
+		tolerance := new(big.Float).Abs(total)
+		tolerance.Mul(tolerance, integralTolerance)
+		if totalErr.Cmp(tolerance) <= 0 || totalErr.Cmp(integralTolerance) <= 0 {
+			return &Estimate{X: total, Error: totalErr}, nil
+		}
+		if len(intervals) >= maxIntervals {
+			return nil, errNoIntegral
+		}
+
+		in := intervals[worst]
+		mid := new(big.Float).Add(in.a, in.b)
+		mid.Quo(mid, big.NewFloat(2))
+		left, err := newInterval(in.a, mid)
+		if err != nil {
+			return nil, err
+		}
+		right, err := newInterval(mid, in.b)
+		if err != nil {
+			return nil, err
+		}
+		intervals[worst] = left
+		intervals = append(intervals, right)
+	}
+}
+
+// gaussKronrod integrates f in [a, b] with the 15 point Kronrod rule, the
+// error is the difference to the embedded 7 point Gauss rule
+func gaussKronrod(f func(x *big.Float) (*big.Float, error), a, b *big.Float) (value, err *big.Float, e error) {
+	center := new(big.Float).Add(a, b)
+	center.Quo(center, big.NewFloat(2))
+	half := new(big.Float).Sub(b, a)
+	half.Quo(half, big.NewFloat(2))
+
+	kronrod := new(big.Float)
+	gauss := new(big.Float)
+	add := func(i int, fx *big.Float) {
+		kronrod.Add(kronrod, new(big.Float).Mul(kronrodWeights[i], fx))
+		if i%2 == 1 {
+			gauss.Add(gauss, new(big.Float).Mul(gaussWeights[i/2], fx))
+		}
+	}
+	for i, node := range kronrodNodes {
+		dx := new(big.Float).Mul(half, node)
+		fx, e := f(new(big.Float).Add(center, dx))
+		if e != nil {
+			return nil, nil, e
+		}
+		if fx.IsInf() {
+			return nil, nil, errNotFinite
+		}
+		add(i, fx)
+		if node.Sign() == 0 {
+			continue
+		}
+		if fx, e = f(new(big.Float).Sub(center, dx)); e != nil {
+			return nil, nil, e
+		}
+		if fx.IsInf() {
+			return nil, nil, errNotFinite
+		}
+		add(i, fx)
+	}
+	kronrod.Mul(kronrod, half)
+	gauss.Mul(gauss, half)
+	err = new(big.Float).Sub(kronrod, gauss)
+	return kronrod, err.Abs(err), nil
+}
+
+// deriv computes the derivative deriv(x^3, x, 2) with central differences
+// extrapolated by the method of Richardson while the error decreases
+func deriv(args []Value) (Value, error) {
+	f, err := bindVariable(args)
+	if err != nil {
+		return nil, err
+	}
+	at, err := realArgs(args[2:])
+	if err != nil {
+		return nil, err
+	}
+	x := at[0]
+	if fx, err := f(x); err != nil {
+		return nil, err
+	} else if fx.IsInf() {
+		return nil, errNotFinite
+	}
+
+	h := new(big.Float).Abs(x)
+	if h.Sign() == 0 {
+		h.SetInt64(1)
+	}
+	h.Mul(h, big.NewFloat(0.1))
+	central := func(h *big.Float) (*big.Float, error) {
+		f1, err := f(new(big.Float).Add(x, h))
+		if err != nil {
+			return nil, err
+		}
+		f0, err := f(new(big.Float).Sub(x, h))
+		if err != nil {
+			return nil, err
+		}
+		d := f1.Sub(f1, f0)
+		return d.Quo(d, new(big.Float).Mul(big.NewFloat(2), h)), nil
+	}
+
+	var best, bestErr *big.Float
+	var prev []*big.Float
+	for i := 0; i < maxRichardsonLen; i++ {
+		d, err := central(h)
+		if err != nil {
+			return nil, err
+		}
+		row := []*big.Float{d}
+		factor := big.NewFloat(4)
+		for j := 1; j <= i; j++ {
+			// cancel the h^2j term of the error
+			num := new(big.Float).Mul(row[j-1], factor)
+			num.Sub(num, prev[j-1])
+			den := new(big.Float).Sub(factor, big.NewFloat(1))
+			row = append(row, num.Quo(num, den))
+			factor.Mul(factor, big.NewFloat(4))
+
+			e1 := new(big.Float).Sub(row[j], row[j-1])
+			e2 := new(big.Float).Sub(row[j], prev[j-1])
+			e := maxFloat(e1.Abs(e1), e2.Abs(e2))
+			if bestErr == nil || e.Cmp(bestErr) <= 0 {
+				best, bestErr = row[j], e
+			}
+		}
+		if i > 0 {
+			// stop when the higher order is worse than the best estimate
+			diff := new(big.Float).Sub(row[i], prev[i-1])
+			if diff.Abs(diff).Cmp(new(big.Float).Mul(big.NewFloat(2), bestErr)) >= 0 {
+				break
+			}
+		}
+		prev = row
+		h.Quo(h, big.NewFloat(2))
+	}
+	return &Estimate{X: best, Error: bestErr}, nil
+}
+
+func maxFloat(x, y *big.Float) *big.Float {
+	if x.Cmp(y) > 0 {
+		return x
+	}
+	return y
+}
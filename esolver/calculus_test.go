@@ -0,0 +1,81 @@
+package esolver
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_esolver_Calculus(t *testing.T) {
+	tests := []struct {
+		expr      string
+		want      string
+		tolerance float64
+	}{
+		{"integrate(x^2, x, 0, 3)", "9", 1e-24},
+		{"integrate(x^2, x, 3, 0)", "-9", 1e-24},
+		{"integrate(x, x, 2, 2)", "0", 0},
+		{"integrate(sin x, x, 0, 180)", "114.591559026164641753596309628", 1e-12},
+		{"integrate(1/sqrt(x), x, 0, 1)", "2", 1e-13},
+		{"integrate(e^(-x^2), x, -5, 5)", "1.77245385090279103", 1e-12},
+		{"integrate(integrate(x*y, y, 0, x), x, 0, 1)", "0.125", 1e-25},
+		{"2*integrate(x, x, 0, 1) + 1", "2", 1e-24},
+		{"deriv(x^3, x, 2)", "12", 1e-60},
+		{"deriv(x^5 - 3x, x, -1.5)", "22.3125", 1e-60},
+		{"deriv(sin x, x, 30)", "0.0151149947019518079919458487468", 1e-14},
+		{"deriv(ln x, x, 2)", "0.5", 1e-14},
+		{"deriv(deriv(x^3, x, y), y, 1)", "6", 1e-30},
+		// the variable shadows the constant only inside the call
+		{"integrate(e, e, 0, 2) + e", "4.71828182845904509079559829843", 1e-15},
+		{"deriv(pi*r^2, r, 2)", "12.5663706143591729538505735331", 1e-15},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			stack, err := e.ParseExpression(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseExpression() failed: %v", err)
+			}
+			x, err := e.SolveStack(stack)
+			if err != nil {
+				t.Fatalf("SolveStack() failed: %v", err)
+			}
+			want, _, _ := big.ParseFloat(tt.want, 10, floatPrec, big.ToNearestEven)
+			diff := new(big.Float).Sub(x, want)
+			if diff.Abs(diff).Cmp(big.NewFloat(tt.tolerance)) > 0 {
+				t.Errorf("SolveStack() = %v, want %v", x.Text('g', 30), tt.want)
+			}
+		})
+	}
+}
+
+func Test_esolver_CalculusEstimate(t *testing.T) {
+	v, err := New().Eval("integrate(sin x, x, 0, 90)")
+	if err != nil {
+		t.Fatalf("Eval() failed: %v", err)
+	}
+	estimate, ok := v.(*Estimate)
+	if !ok {
+		t.Fatalf("Eval() = %T, want *Estimate", v)
+	}
+	if estimate.Error.Sign() <= 0 || estimate.Error.Cmp(big.NewFloat(1e-12)) > 0 {
+		t.Errorf("Error = %v", estimate.Error)
+	}
+}
+
+func Test_esolver_CalculusErrors(t *testing.T) {
+	e := New()
+	for _, expr := range []string{
+		"integrate(1/x, x, -1, 1)",
+		"integrate(x, 3, 0, 1)",
+		"integrate(x, x, 0, [1, 2])",
+		"integrate(x*y, x, 0, 1)",
+		"integrate(x, x, 0)",
+		"deriv(1/x, x, 0)",
+		"deriv(sqrt x, x, 0)",
+		"deriv(x^2, x, 1) + x",
+	} {
+		if v, err := e.Eval(expr); err == nil {
+			t.Errorf("Eval(%q) = %v, expected error", expr, v)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package esolver
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"math/cmplx"
+)
+
+var errDivisionByZero = errors.New("division by zero")
+
+// Complex is a complex number with arbitrary precision parts
+type Complex struct {
+	Re *big.Float
+	Im *big.Float
+}
+
+// NewComplex creates a complex number from its rectangular parts
+func NewComplex(re, im *big.Float) *Complex {
+	return &Complex{Re: re, Im: im}
+}
+
+// NewPolar creates a complex number from its magnitude and angle in degrees,
+// the parts have float64 precision like the trigonometric functions
+func NewPolar(r, theta *big.Float) *Complex {
+	t, _ := theta.Float64()
+	s, c := sincosDeg(t)
+	return &Complex{
+		Re: new(big.Float).SetPrec(53).Mul(r, big.NewFloat(c)),
+		Im: new(big.Float).SetPrec(53).Mul(r, big.NewFloat(s)),
+	}
+}
+
+func (c *Complex) String() string {
+	im := c.Im.Text('g', 10)
+	if c.Im.Sign() >= 0 {
+		im = "+" + im
+	}
+	return c.Re.Text('g', 10) + im + "i"
+}
+
+// Abs returns the magnitude of the complex number
+func (c *Complex) Abs() *big.Float {
+	re := new(big.Float).Mul(c.Re, c.Re)
+	im := new(big.Float).Mul(c.Im, c.Im)
+	return re.Add(re, im).Sqrt(re)
+}
+
+// Arg returns the angle of the complex number in degrees
+func (c *Complex) Arg() *big.Float {
+	re, _ := c.Re.Float64()
+	im, _ := c.Im.Float64()
+	return big.NewFloat(math.Atan2(im, re) * radToDeg)
+}
+
+func toComplex(v Value) *Complex {
+	switch x := v.(type) {
+	case *Complex:
+		return x
+	case *big.Float:
+		return &Complex{Re: x, Im: new(big.Float)}
+	}
+	return nil
+}
+
+func (c *Complex) complex128() complex128 {
+	re, _ := c.Re.Float64()
+	im, _ := c.Im.Float64()
+	return complex(re, im)
+}
+
+func fromComplex128(z complex128) (*Complex, error) {
+	if cmplx.IsNaN(z) {
+		return nil, errDomain
+	}
+	return &Complex{Re: big.NewFloat(real(z)), Im: big.NewFloat(imag(z))}, nil
+}
+
+// sincosDeg returns the sine and cosine of an angle in degrees, exact on
+// the quadrant angles
+func sincosDeg(deg float64) (s, c float64) {
+	if q := deg / 90; q == math.Trunc(q) {
+		switch int64(math.Mod(q, 4)+4) % 4 {
+		case 0:
+			return 0, 1
+		case 1:
+			return 1, 0
+		case 2:
+			return 0, -1
+		case 3:
+			return -1, 0
+		}
+	}
+	return math.Sincos(deg * degToRad)
+}
+
+func complexAdd(x, y *Complex) *Complex {
+	return &Complex{
+		Re: new(big.Float).Add(x.Re, y.Re),
+		Im: new(big.Float).Add(x.Im, y.Im),
+	}
+}
+
+func complexSub(x, y *Complex) *Complex {
+	return &Complex{
+		Re: new(big.Float).Sub(x.Re, y.Re),
+		Im: new(big.Float).Sub(x.Im, y.Im),
+	}
+}
+
+func complexMul(x, y *Complex) *Complex {
+	ac := new(big.Float).Mul(x.Re, y.Re)
+	bd := new(big.Float).Mul(x.Im, y.Im)
+	ad := new(big.Float).Mul(x.Re, y.Im)
+	bc := new(big.Float).Mul(x.Im, y.Re)
+	return &Complex{Re: ac.Sub(ac, bd), Im: ad.Add(ad, bc)}
+}
+
+func complexQuo(x, y *Complex) (*Complex, error) {
+	den := new(big.Float).Mul(y.Re, y.Re)
+	den.Add(den, new(big.Float).Mul(y.Im, y.Im))
+	if den.Sign() == 0 {
+		return nil, errDivisionByZero
+	}
+	ac := new(big.Float).Mul(x.Re, y.Re)
+	bd := new(big.Float).Mul(x.Im, y.Im)
+	bc := new(big.Float).Mul(x.Im, y.Re)
+	ad := new(big.Float).Mul(x.Re, y.Im)
+	re := ac.Add(ac, bd)
+	im := bc.Sub(bc, ad)
+	return &Complex{Re: re.Quo(re, den), Im: im.Quo(im, den)}, nil
+}
+
+func complexPow(x, y *Complex) (*Complex, error) {
+	if y.Im.Sign() == 0 && y.Re.IsInt() {
+		if n, acc := y.Re.Int64(); acc == big.Exact && n > -1024 && n < 1024 {
+			return complexPowInt(x, n)
+		}
+	}
+	return fromComplex128(cmplx.Pow(x.complex128(), y.complex128()))
+}
+
+// complexPowInt raises x to an integer power by repeated squaring keeping
+// the full precision
+func complexPowInt(x *Complex, n int64) (*Complex, error) {
+	one := &Complex{Re: big.NewFloat(1), Im: new(big.Float)}
+	if n < 0 {
+		inv, err := complexQuo(one, x)
+		if err != nil {
+			return nil, err
+		}
+		x, n = inv, -n
+	}
+	result := one
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result = complexMul(result, x)
+		}
+		x = complexMul(x, x)
+	}
+	return result, nil
+}
+
+// complexSqrt returns the principal square root keeping the full precision
+func complexSqrt(x *Complex) *Complex {
+	abs := x.Abs()
+	re := new(big.Float).Add(abs, x.Re)
+	re.Quo(re, big.NewFloat(2)).Sqrt(re)
+	im := new(big.Float).Sub(abs, x.Re)
+	im.Quo(im, big.NewFloat(2)).Sqrt(im)
+	if x.Im.Sign() < 0 {
+		im.Neg(im)
+	}
+	return &Complex{Re: re, Im: im}
+}
+
+func complexDeg(fx func(complex128) complex128) func(*Complex) (*Complex, error) {
+	return func(x *Complex) (*Complex, error) {
+		return fromComplex128(fx(x.complex128() * degToRad))
+	}
+}
+
+func complexToDeg(fx func(complex128) complex128) func(*Complex) (*Complex, error) {
+	return func(x *Complex) (*Complex, error) {
+		return fromComplex128(fx(x.complex128()) * radToDeg)
+	}
+}
+
+var complexFuncs = map[string]func(*Complex) (*Complex, error){
+	"ln":   func(x *Complex) (*Complex, error) { return fromComplex128(cmplx.Log(x.complex128())) },
+	"sqrt": func(x *Complex) (*Complex, error) { return complexSqrt(x), nil },
+	"cbrt": func(x *Complex) (*Complex, error) { return fromComplex128(cmplx.Pow(x.complex128(), 1.0/3)) },
+	"cos":  complexDeg(cmplx.Cos),
+	"sin":  complexDeg(cmplx.Sin),
+	"tan":  complexDeg(cmplx.Tan),
+	"acos": complexToDeg(cmplx.Acos),
+	"asin": complexToDeg(cmplx.Asin),
+	"atan": complexToDeg(cmplx.Atan),
+	"abs":  func(x *Complex) (*Complex, error) { return toComplex(x.Abs()), nil },
+	"arg":  func(x *Complex) (*Complex, error) { return toComplex(x.Arg()), nil },
+	"re":   func(x *Complex) (*Complex, error) { return toComplex(x.Re), nil },
+	"im":   func(x *Complex) (*Complex, error) { return toComplex(x.Im), nil },
+	"conj": func(x *Complex) (*Complex, error) {
+		return &Complex{Re: x.Re, Im: new(big.Float).Neg(x.Im)}, nil
+	},
+}
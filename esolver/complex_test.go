@@ -0,0 +1,79 @@
+package esolver
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_esolver_EvalComplex(t *testing.T) {
+	e := New()
+	e.SetComplexMode(true)
+
+	assert := func(value string, re, im float64) {
+		v, err := e.Eval(value)
+		if err != nil {
+			t.Errorf("Value:`%v` unexpected error:%v", value, err)
+			return
+		}
+		z := toComplex(v)
+		if z == nil {
+			t.Errorf("Value:`%v` result is not a number:%v", value, v)
+			return
+		}
+		for _, part := range []struct{ got, want *big.Float }{{z.Re, big.NewFloat(re)}, {z.Im, big.NewFloat(im)}} {
+			diff := new(big.Float).Sub(part.got, part.want)
+			diff.Abs(diff)
+			if diff.Cmp(big.NewFloat(0.0000000001)) > 0 {
+				t.Errorf("Value:`%v` expected:`%v%+vi` result:`%v`", value, re, im, z)
+				return
+			}
+		}
+	}
+
+	assert("3+4i", 3, 4)
+	assert("3-4j", 3, -4)
+	assert("i^2", -1, 0)
+	assert("i^-1", 0, -1)
+	assert("(3+4i)*(3-4i)", 25, 0)
+	assert("(1+2i)/(3-4i)", -0.2, 0.4)
+	assert("abs(3+4i)", 5, 0)
+	assert("arg(1+i)", 45, 0)
+	assert("arg(-2)", 180, 0)
+	assert("conj(3+4i)", 3, -4)
+	assert("re(3+4i)", 3, 0)
+	assert("im(3+4i)", 4, 0)
+	assert("sqrt(-4)", 0, 2)
+	assert("sqrt(3+4i)", 2, 1)
+	assert("ln(-1)", 0, 3.14159265358979)
+	assert("(-4)^0.5", 0, 2)
+	assert("10∠30", 8.660254037844387, 5)
+	assert("10@90", 0, 10)
+	assert("2∠180+2", 0, 0)
+	assert("2*10∠90", 0, 20)
+	assert("-(1+i)", -1, -1)
+}
+
+func Test_esolver_ComplexMode(t *testing.T) {
+	e := New()
+	for _, expr := range []string{"sqrt(-4)", "ln(-1)", "acos2", "(-4)^0.5"} {
+		if _, err := e.Eval(expr); err == nil {
+			t.Errorf("Value:`%v` expected error out of complex mode", expr)
+		}
+	}
+
+	if _, err := e.Solve("3+4i"); err != errNotReal {
+		t.Errorf("Solve complex expected error %v got %v", errNotReal, err)
+	}
+
+	if x, err := e.Solve("(2i)^2"); err != nil || x.Cmp(big.NewFloat(-4)) != 0 {
+		t.Errorf("Solve (2i)^2 expected -4 got %v %v", x, err)
+	}
+
+	if _, err := e.Eval("(1+i)∠30"); err == nil {
+		t.Errorf("expected error on complex polar magnitude")
+	}
+
+	if _, err := e.Eval("0/0"); err == nil {
+		t.Errorf("expected error on 0/0")
+	}
+}
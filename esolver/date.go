@@ -0,0 +1,223 @@
+package esolver
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// dateLayout is the layout of the date literals like 2026-10-18
+const dateLayout = "2006-01-02"
+
+// clock returns the current time of today and now
+var clock = time.Now
+
+// Date is a calendar date with the time of the day given by the date
+// literals like 2026-10-18 and the constants today and now. The dates are
+// civil times without time zone, so every day has 24 hours. Adding hours to
+// a date returns a date and subtracting two dates returns the hours
+// between them
+type Date struct {
+	time.Time
+}
+
+// String writes the date as 2026-10-18, adding the time of the day when it
+// is not midnight
+func (d *Date) String() string {
+	switch {
+	case d.Nanosecond() != 0:
+		return d.Format("2006-01-02 15:04:05.000")
+	case d.Second() != 0:
+		return d.Format("2006-01-02 15:04:05")
+	case d.Hour() != 0 || d.Minute() != 0:
+		return d.Format("2006-01-02 15:04")
+	}
+	return d.Format(dateLayout)
+}
+
+// civil keeps the date and the clock time of t as a time in UTC
+func civil(t time.Time) *Date {
+	y, m, d := t.Date()
+	return &Date{time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)}
+}
+
+var dateConsts = map[string]func() *Date{
+	"today": func() *Date {
+		y, m, d := clock().Date()
+		return &Date{time.Date(y, m, d, 0, 0, 0, 0, time.UTC)}
+	},
+	"now": func() *Date { return civil(clock()) },
+}
+
+// isDate reports whether the name is a date literal, today or now
+func isDate(name string) bool {
+	if _, ok := dateConsts[name]; ok {
+		return true
+	}
+	_, ok := parseDate(name)
+	return ok
+}
+
+// parseDate returns the date of a literal like 2026-10-18
+func parseDate(s string) (*Date, bool) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return nil, false
+	}
+	return &Date{t}, true
+}
+
+// hours returns the hours since 1970-01-01
+func (d *Date) hours() *big.Float {
+	h := new(big.Float).SetPrec(floatPrec).SetInt64(d.Unix())
+	h.Add(h, new(big.Float).SetPrec(floatPrec).Quo(big.NewFloat(float64(d.Nanosecond())), big.NewFloat(1e9)))
+	return h.Quo(h, big.NewFloat(3600))
+}
+
+// addHours returns the date plus the hours rounded to nanoseconds
+func (d *Date) addHours(hours *big.Float) (*Date, error) {
+	ns := new(big.Float).Mul(hours, big.NewFloat(3600e9))
+	n, _ := ns.Int(nil)
+	if !n.IsInt64() {
+		return nil, errors.New("the date is out of range")
+	}
+	sec, nsec := new(big.Int).QuoRem(n, big.NewInt(1e9), new(big.Int))
+	return &Date{time.Unix(d.Unix()+sec.Int64(), int64(d.Nanosecond())+nsec.Int64()).UTC()}, nil
+}
+
+// dateOperator adds and subtracts hours to dates, subtracts and compares
+// dates
+func dateOperator(name string, x, y Value) (Value, error) {
+	xd, xDate := x.(*Date)
+	yd, yDate := y.(*Date)
+	switch {
+	case xDate && yDate:
+		if name == "-" {
+			return new(big.Float).Sub(xd.hours(), yd.hours()), nil
+		}
+		c := xd.Compare(yd.Time)
+		switch name {
+		case "<":
+			return &Bool{Value: c < 0}, nil
+		case "<=":
+			return &Bool{Value: c <= 0}, nil
+		case ">":
+			return &Bool{Value: c > 0}, nil
+		case ">=":
+			return &Bool{Value: c >= 0}, nil
+		case "==":
+			return &Bool{Value: c == 0}, nil
+		case "!=":
+			return &Bool{Value: c != 0}, nil
+		}
+	case xDate && (name == "+" || name == "-"):
+		h, err := Real(y)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s operand not supported", name, typeName(y))
+		}
+		if name == "-" {
+			h = new(big.Float).Neg(h)
+		}
+		return xd.addHours(h)
+	case yDate && name == "+":
+		h, err := Real(x)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s operand not supported", name, typeName(x))
+		}
+		return yd.addHours(h)
+	}
+	return nil, fmt.Errorf("%s: date operands not supported", name)
+}
+
+// marked is a value with the markers d, m and s read as a time in hours,
+// days reports whether it has the d of days
+type marked struct {
+	hours Value
+	days  bool
+}
+
+// durationArgs are the functions whose arguments are durations, so days(90d)
+// is 90
+var durationArgs = map[string]bool{"days": true}
+
+// readTimes replaces the values with the markers d, m and s by their
+// reading as times
+func readTimes(args []Value, kinds []Kind, times []*marked) {
+	for i, t := range times {
+		if t != nil {
+			args[i], kinds[i], times[i] = t.hours, Duration, nil
+		}
+	}
+}
+
+// readsTime reports whether a value with the markers d, m and s is a time
+// as the operand of the other one: added to, subtracted from or compared
+// with a date, or without days with a duration, so the 45m of 2h + 45m are
+// minutes but 45d30m + 1h is an angle
+func readsTime(name string, t *marked, other Value, otherKind Kind) bool {
+	switch name {
+	case "+", "-", "<", "<=", ">", ">=", "==", "!=":
+	default:
+		return false
+	}
+	if _, ok := other.(*Date); ok {
+		return t != nil
+	}
+	return t != nil && !t.days && otherKind == Duration
+}
+
+// markedOperator applies the operator returning the kind of the result and
+// its reading as a time. The values with the markers d, m and s are read as
+// times by their operands, otherwise they are degrees, and a plain number
+// added to a date is a number of days. The reading is kept by their sums
+// and their products by plain numbers, so 2026-10-18 + 2*45d adds 90 days
+func (e *esolver) markedOperator(name string, args []Value, kinds []Kind, times []*marked) (Value, Kind, *marked, error) {
+	if readsTime(name, times[0], args[1], kinds[1]) || readsTime(name, times[1], args[0], kinds[0]) {
+		readTimes(args, kinds, times)
+	}
+	x, y := args[0], args[1]
+	var err error
+	_, xDate := x.(*Date)
+	_, yDate := y.(*Date)
+	switch {
+	case xDate && !yDate && kinds[1] != Duration && (name == "+" || name == "-"):
+		y, err = e.applyOperator("*", y, big.NewFloat(24))
+	case yDate && !xDate && kinds[0] != Duration && name == "+":
+		x, err = e.applyOperator("*", x, big.NewFloat(24))
+	}
+	if err != nil {
+		return nil, Plain, nil, err
+	}
+	z, err := e.applyOperator(name, x, y)
+	if err != nil {
+		return nil, Plain, nil, err
+	}
+
+	var t *marked
+	var hours Value
+	switch {
+	case times[0] != nil && times[1] != nil && (name == "+" || name == "-"):
+		hours, err = e.applyOperator(name, times[0].hours, times[1].hours)
+		t = &marked{hours, times[0].days || times[1].days}
+	case times[0] != nil && times[1] == nil && kinds[1] == Plain && (name == "*" || name == "/"):
+		hours, err = e.applyOperator(name, times[0].hours, y)
+		t = &marked{hours, times[0].days}
+	case times[1] != nil && times[0] == nil && kinds[0] == Plain && name == "*":
+		hours, err = e.applyOperator(name, x, times[1].hours)
+		t = &marked{hours, times[1].days}
+	}
+	if err != nil {
+		return nil, Plain, nil, err
+	}
+	return z, operatorKind(name, kinds[0], kinds[1]), t, nil
+}
+
+// days converts the hours of a duration to days
+func days(args []Value) (Value, error) {
+	h, err := Real(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("%s argument not supported", typeName(args[0]))
+	}
+	return new(big.Float).SetPrec(floatPrec).Quo(h, big.NewFloat(24)), nil
+}
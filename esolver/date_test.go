@@ -0,0 +1,89 @@
+package esolver
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func Test_esolver_Date(t *testing.T) {
+	clock = func() time.Time { return time.Date(2026, 10, 18, 14, 30, 0, 0, time.Local) }
+	defer func() { clock = time.Now }()
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"2026-10-18 + 90d", "2027-01-16"},
+		{"90d + 2026-10-18", "2027-01-16"},
+		{"2026-10-18 - 1d", "2026-10-17"},
+		{"2026-10-18 + 2h 30m", "2026-10-18 02:30"},
+		{"2026-10-18 + 10s", "2026-10-18 00:00:10"},
+		{"2024-02-28 + 1d", "2024-02-29"},
+		{"today", "2026-10-18"},
+		{"now", "2026-10-18 14:30"},
+		{"today + 68d", "2026-12-25"},
+		{"2026-12-25 - today", "1632"},
+		{"days(2026-12-25 - today)", "68"},
+		{"days(now - today)", "0.60416666666666666667"},
+		{"2h 35m 10s + 45m", "3.3361111111111111111"},
+		{"2026-10-18 < 2026-12-25", "true"},
+		{"2026-10-18 == today", "true"},
+		{"(2026-10-18) + 90d", "2027-01-16"},
+		{"2026-10-18 + 2*45d", "2027-01-16"},
+		{"2026-10-18 - (90d)/2", "2026-09-03"},
+		{"2026-10-18 + 1", "2026-10-19"},
+		{"2026-10-18 + 1.5", "2026-10-19 12:00"},
+		{"1 + 2026-10-18", "2026-10-19"},
+		{"2026-10-18 + 45d - 2*30m", "2026-12-01 23:00"},
+		{"days(90d)", "90"},
+		{"days(2026-10-18 + 90d - 2026-10-18)", "90"},
+		{"45d30m + 1h", "46.5"},
+		{"2h 30m + 45m", "3.25"},
+		{"-45m + 2h", "1.25"},
+		{"45m < 1h", "true"},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := e.Eval(tt.expr)
+			if err != nil {
+				t.Fatalf("Eval() failed: %v", err)
+			}
+			var s string
+			switch v := got.(type) {
+			case *big.Float:
+				s = v.Text('g', 20)
+			case interface{ String() string }:
+				s = v.String()
+			}
+			if s != tt.want {
+				t.Errorf("Eval() = %v, want %v", s, tt.want)
+			}
+		})
+	}
+}
+
+func Test_esolver_DateErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"2026-10-18 * 2", "*: date operands not supported"},
+		{"2026-10-18 + 2026-10-19", "+: date operands not supported"},
+		{"5 - 2026-10-18", "-: date operands not supported"},
+		{"2026-10-18 + [1, 2]", "+: vector operand not supported"},
+		{"sin(today)", "sin: date argument not supported"},
+		{"days(today)", "days: date argument not supported"},
+		{"2026-13-01", `unknown name "2026-13-01"`},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := e.Eval(tt.expr)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Eval() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
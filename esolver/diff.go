@@ -0,0 +1,175 @@
+package esolver
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// degToRadNode converts the angles of the trigonometric functions, they
+// work in degrees
+var degToRadNode = &BinaryOp{"/", &Ident{"pi"}, number(big.NewRat(180, 1))}
+
+// derivatives returns the derivative of the functions of one argument at u
+var derivatives = map[string]func(u Node) Node{
+	"ln": func(u Node) Node { return &BinaryOp{"/", number(big.NewRat(1, 1)), u} },
+	"sqrt": func(u Node) Node {
+		return &BinaryOp{"/", number(big.NewRat(1, 1)), &BinaryOp{"*", number(big.NewRat(2, 1)), &Call{"sqrt", []Node{u}}}}
+	},
+	"cbrt": func(u Node) Node {
+		cbrt := &BinaryOp{"^", &Call{"cbrt", []Node{u}}, number(big.NewRat(2, 1))}
+		return &BinaryOp{"/", number(big.NewRat(1, 1)), &BinaryOp{"*", number(big.NewRat(3, 1)), cbrt}}
+	},
+	"abs": func(u Node) Node { return &BinaryOp{"/", u, &Call{"abs", []Node{u}}} },
+	"sin": func(u Node) Node { return &BinaryOp{"*", degToRadNode, &Call{"cos", []Node{u}}} },
+	"cos": func(u Node) Node {
+		return &UnaryOp{"-", &BinaryOp{"*", degToRadNode, &Call{"sin", []Node{u}}}}
+	},
+	"tan": func(u Node) Node {
+		cos2 := &BinaryOp{"^", &Call{"cos", []Node{u}}, number(big.NewRat(2, 1))}
+		return &BinaryOp{"/", degToRadNode, cos2}
+	},
+	"asin": func(u Node) Node {
+		return &BinaryOp{"/", number(big.NewRat(1, 1)), &BinaryOp{"*", degToRadNode, sqrt1MinusSquare(u)}}
+	},
+	"acos": func(u Node) Node {
+		return &UnaryOp{"-", &BinaryOp{"/", number(big.NewRat(1, 1)), &BinaryOp{"*", degToRadNode, sqrt1MinusSquare(u)}}}
+	},
+	"atan": func(u Node) Node {
+		square := &BinaryOp{"+", number(big.NewRat(1, 1)), &BinaryOp{"^", u, number(big.NewRat(2, 1))}}
+		return &BinaryOp{"/", number(big.NewRat(1, 1)), &BinaryOp{"*", degToRadNode, square}}
+	},
+	// zero except at the steps
+	"ceil":  func(u Node) Node { return number(new(big.Rat)) },
+	"floor": func(u Node) Node { return number(new(big.Rat)) },
+}
+
+func sqrt1MinusSquare(u Node) Node {
+	return &Call{"sqrt", []Node{&BinaryOp{"-", number(big.NewRat(1, 1)), &BinaryOp{"^", u, number(big.NewRat(2, 1))}}}}
+}
+
+// diff returns the derivative diff(x^2*sin(x), x) as a simplified
+// expression, the variable may be omitted when there is a single unknown
+func diff(args []Value) (Value, error) {
+	expr := args[0].(*Expr)
+	name, err := variableName(expr, args[1:])
+	if err != nil {
+		return nil, err
+	}
+	tree, err := buildTree(expr.postfix.Values)
+	if err != nil {
+		return nil, err
+	}
+	d, err := derivative(tree, name)
+	if err != nil {
+		return nil, err
+	}
+	if expr.solver.angleUnit == Radians {
+		d = removeDegToRad(d)
+	}
+	return simplify(d), nil
+}
+
+// removeDegToRad replaces the conversions of the derivatives of the
+// trigonometric functions by 1 when they work in radians
+func removeDegToRad(n Node) Node {
+	switch x := n.(type) {
+	case *UnaryOp:
+		return &UnaryOp{x.Op, removeDegToRad(x.X)}
+	case *BinaryOp:
+		if x == degToRadNode {
+			return number(big.NewRat(1, 1))
+		}
+		return &BinaryOp{x.Op, removeDegToRad(x.X), removeDegToRad(x.Y)}
+	case *Call:
+		args := make([]Node, len(x.Args))
+		for i, arg := range x.Args {
+			args[i] = removeDegToRad(arg)
+		}
+		return &Call{x.Func, args}
+	}
+	return n
+}
+
+// derivative differentiates the tree with respect to the variable x
+func derivative(n Node, x string) (Node, error) {
+	switch n := n.(type) {
+	case *Number:
+		return number(new(big.Rat)), nil
+	case *Ident:
+		if n.Name == x {
+			return number(big.NewRat(1, 1)), nil
+		}
+		return number(new(big.Rat)), nil
+	case *UnaryOp:
+		if n.Op != "-" && n.Op != "+" {
+			return nil, fmt.Errorf("operator %s can not be differentiated", n.Op)
+		}
+		du, err := derivative(n.X, x)
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryOp{n.Op, du}, nil
+	case *BinaryOp:
+		return derivativeOperator(n, x)
+	case *Call:
+		fx, ok := derivatives[n.Func]
+		if !ok || len(n.Args) != 1 {
+			return nil, fmt.Errorf("%s can not be differentiated", n.Func)
+		}
+		du, err := derivative(n.Args[0], x)
+		if err != nil {
+			return nil, err
+		}
+		return &BinaryOp{"*", fx(n.Args[0]), du}, nil
+	case *List:
+		return nil, errors.New("vectors can not be differentiated")
+	}
+	return nil, errInvalidExpression
+}
+
+func derivativeOperator(n *BinaryOp, x string) (Node, error) {
+	u, v := n.X, n.Y
+	du, err := derivative(u, x)
+	if err != nil {
+		return nil, err
+	}
+	dv, err := derivative(v, x)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.Op {
+	case "+", "-":
+		return &BinaryOp{n.Op, du, dv}, nil
+	case "*":
+		return &BinaryOp{"+", &BinaryOp{"*", du, v}, &BinaryOp{"*", u, dv}}, nil
+	case "/":
+		num := &BinaryOp{"-", &BinaryOp{"*", du, v}, &BinaryOp{"*", u, dv}}
+		return &BinaryOp{"/", num, &BinaryOp{"^", v, number(big.NewRat(2, 1))}}, nil
+	case "^":
+		if !dependsOn(v, x) {
+			// v*u^(v-1)*du
+			exp := &BinaryOp{"-", v, number(big.NewRat(1, 1))}
+			return &BinaryOp{"*", &BinaryOp{"*", v, &BinaryOp{"^", u, exp}}, du}, nil
+		}
+		if !dependsOn(u, x) {
+			// u^v*ln(u)*dv
+			if e, ok := u.(*Ident); ok && e.Name == "e" {
+				return &BinaryOp{"*", n, dv}, nil
+			}
+			return &BinaryOp{"*", &BinaryOp{"*", n, &Call{"ln", []Node{u}}}, dv}, nil
+		}
+		// u^v*(dv*ln(u) + v*du/u)
+		ln := &Call{"ln", []Node{u}}
+		sum := &BinaryOp{"+", &BinaryOp{"*", dv, ln}, &BinaryOp{"/", &BinaryOp{"*", v, du}, u}}
+		return &BinaryOp{"*", n, sum}, nil
+	}
+	return nil, fmt.Errorf("operator %s can not be differentiated", n.Op)
+}
+
+// dependsOn reports whether the variable x appears in the tree
+func dependsOn(n Node, x string) bool {
+	return slices.Contains(Idents(n), x)
+}
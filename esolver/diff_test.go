@@ -0,0 +1,112 @@
+package esolver
+
+import (
+	"testing"
+)
+
+func parseTree(t *testing.T, s string) Node {
+	t.Helper()
+	n, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	return n
+}
+
+func Test_simplify(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"1 + 2*3", "7"},
+		{"x + 0", "x"},
+		{"0 - x", "-x"},
+		{"x*1", "x"},
+		{"0*x + y", "y"},
+		{"x/1", "x"},
+		{"x^1", "x"},
+		{"x^0", "1"},
+		{"1^x", "1"},
+		{"x + x", "2*x"},
+		{"2*x + 3*x - y", "5*x - y"},
+		{"x - x", "0"},
+		{"x*x*x", "x^3"},
+		{"x^2*x^-2", "1"},
+		{"x*2", "2*x"},
+		{"sin(x)*x", "x*sin(x)"},
+		{"x*sin(x) + sin(x)*x", "2*x*sin(x)"},
+		{"x/2 + x/3", "5*x/6"},
+		{"2^10", "1024"},
+		{"2^-2", "1/4"},
+		{"(x^2)^3", "x^6"},
+		{"-(a - b)", "-a + b"},
+		{"a*b/(a*c)", "b/c"},
+		{"1 + 1/3", "4/3"},
+		{"0.1 + 0.2", "3/10"},
+		{"sin(0*x + 30)", "sin(30)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if got := simplify(parseTree(t, tt.expr)).String(); got != tt.want {
+				t.Errorf("simplify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_esolver_Diff(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"diff(x^2*sin(x), x)", "2*x*sin(x) + x^2*pi*cos(x)/180"},
+		{"diff(x^3 - 2x + 5)", "3*x^2 - 2"},
+		{"diff(a*x^2 + b*x + c, x)", "2*a*x + b"},
+		{"diff(1/x)", "-1/x^2"},
+		{"diff(x/(1 + x))", "1/(x + 1)^2"},
+		{"diff(sqrt(x))", "1/(2*sqrt(x))"},
+		{"diff(x^0.5)", "1/(2*x^(1/2))"},
+		{"diff(e^(2x))", "2*e^(2*x)"},
+		{"diff(2^x)", "2^x*ln(2)"},
+		{"diff(x^x)", "x^x*(ln(x) + 1)"},
+		{"diff(ln(x^2))", "2/x"},
+		{"diff(sin(x)^2)", "pi*sin(x)*cos(x)/90"},
+		{"diff(cos(2x))", "-pi*sin(2*x)/90"},
+		{"diff(atan(x))", "180/(pi*(x^2 + 1))"},
+		// the variable shadows the constant e
+		{"diff(e^2, e)", "2*e"},
+		{"diff(x*y, y)", "x"},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			v, err := e.Eval(tt.expr)
+			if err != nil {
+				t.Fatalf("Eval() failed: %v", err)
+			}
+			n, ok := v.(Node)
+			if !ok {
+				t.Fatalf("Eval() = %T, want Node", v)
+			}
+			if got := n.String(); got != tt.want {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_esolver_DiffErrors(t *testing.T) {
+	e := New()
+	for _, expr := range []string{
+		"diff(x*y)",
+		"diff(dot(x, x), x)",
+		"diff([x, 1], x)",
+		"diff(x^2, 2)",
+		"diff(x^2) + 1",
+		"sin(diff(x^2))",
+	} {
+		if v, err := e.Eval(expr); err == nil {
+			t.Errorf("Eval(%q) = %v, expected error", expr, v)
+		}
+	}
+}
@@ -0,0 +1,462 @@
+package esolver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+const (
+	// distEpsilon is the relative accuracy of the series and continued
+	// fractions of the incomplete gamma and beta functions
+	distEpsilon = 1e-16
+	distMaxIter = 1000
+	// maxTerms limits the terms summed by the discrete distributions
+	maxTerms = 1000000
+)
+
+var (
+	errProbability = errors.New("the probability must be between 0 and 1")
+	errPositive    = errors.New("the parameter must be positive")
+)
+
+// floatArgs converts the arguments of the continuous distributions
+func floatArgs(args []Value) ([]float64, error) {
+	xs := make([]float64, len(args))
+	for i, arg := range args {
+		x, err := Real(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%s argument not supported", typeName(arg))
+		}
+		xs[i], _ = x.Float64()
+	}
+	return xs, nil
+}
+
+// locationScale returns mu and sigma of normcdf(x, mu, sigma), 0 and 1
+// when they are omitted
+func locationScale(xs []float64) (mu, sigma float64, err error) {
+	switch len(xs) {
+	case 1:
+		return 0, 1, nil
+	case 3:
+		if xs[2] <= 0 {
+			return 0, 0, errors.New("sigma must be positive")
+		}
+		return xs[1], xs[2], nil
+	}
+	return 0, 0, errors.New("give both mu and sigma")
+}
+
+// normcdf returns the normal cumulative distribution normcdf(x, mu, sigma)
+func normcdf(args []Value) (Value, error) {
+	xs, err := floatArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	mu, sigma, err := locationScale(xs)
+	if err != nil {
+		return nil, err
+	}
+	return big.NewFloat(normalCDF((xs[0] - mu) / sigma)), nil
+}
+
+// norminv returns the inverse of the normal cumulative distribution
+func norminv(args []Value) (Value, error) {
+	xs, err := floatArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	mu, sigma, err := locationScale(xs)
+	if err != nil {
+		return nil, err
+	}
+	p, q, err := probability(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return big.NewFloat(mu + sigma*normalInv(p, q)), nil
+}
+
+// probability returns p and 1 - p, computed before the conversion to keep
+// the accuracy of the upper tail
+func probability(v Value) (p, q float64, err error) {
+	x, err := Real(v)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s argument not supported", typeName(v))
+	}
+	if x.Sign() <= 0 || x.Cmp(big.NewFloat(1)) >= 0 {
+		return 0, 0, errProbability
+	}
+	p, _ = x.Float64()
+	q, _ = new(big.Float).Sub(big.NewFloat(1), x).Float64()
+	return p, q, nil
+}
+
+func normalCDF(z float64) float64 {
+	return 0.5 * math.Erfc(-z/math.Sqrt2)
+}
+
+// normalInv is the rational approximation of Acklam, with relative error
+// 1.15e-9, refined with a step of Halley's method. q is 1 - p
+func normalInv(p, q float64) float64 {
+	a := [6]float64{-3.969683028665376e+01, 2.209460984245205e+02, -2.759285104469687e+02,
+		1.383577518672690e+02, -3.066479806614716e+01, 2.506628277459239e+00}
+	b := [5]float64{-5.447609879822406e+01, 1.615858368580409e+02, -1.556989798598866e+02,
+		6.680131188771972e+01, -1.328068155288572e+01}
+	c := [6]float64{-7.784894002430293e-03, -3.223964580411365e-01, -2.400758277161838e+00,
+		-2.549732539343734e+00, 4.374664141464968e+00, 2.938163982698783e+00}
+	d := [4]float64{7.784695709041462e-03, 3.224671290700398e-01, 2.445134137142996e+00,
+		3.754408661907416e+00}
+	const pLow = 0.02425
+
+	tail := func(q float64) float64 {
+		return (((((c[0]*q+c[1])*q+c[2])*q+c[3])*q+c[4])*q + c[5]) /
+			((((d[0]*q+d[1])*q+d[2])*q+d[3])*q + 1)
+	}
+	var x float64
+	switch {
+	case p < pLow:
+		x = tail(math.Sqrt(-2 * math.Log(p)))
+	case q < pLow:
+		x = -tail(math.Sqrt(-2 * math.Log(q)))
+	default:
+		q := p - 0.5
+		r := q * q
+		x = (((((a[0]*r+a[1])*r+a[2])*r+a[3])*r+a[4])*r + a[5]) * q /
+			(((((b[0]*r+b[1])*r+b[2])*r+b[3])*r+b[4])*r + 1)
+	}
+
+	e := normalCDF(x) - p
+	if p > 0.5 {
+		// the upper tail keeps the relative accuracy of q
+		e = q - normalCDF(-x)
+	}
+	u := e * math.Sqrt(2*math.Pi) * math.Exp(x*x/2)
+	return x - u/(1+x*u/2)
+}
+
+// tcdf returns the Student's t cumulative distribution tcdf(t, nu)
+func tcdf(args []Value) (Value, error) {
+	xs, err := floatArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	t, nu := xs[0], xs[1]
+	if nu <= 0 {
+		return nil, errPositive
+	}
+	if t > 0 {
+		return big.NewFloat(1 - tTail(t, nu)), nil
+	}
+	return big.NewFloat(tTail(t, nu)), nil
+}
+
+// tTail returns the probability of a value beyond |t|
+func tTail(t, nu float64) float64 {
+	return 0.5 * betaRegularized(nu/2, 0.5, nu/(nu+t*t))
+}
+
+func tPDF(t, nu float64) float64 {
+	lg1, _ := math.Lgamma((nu + 1) / 2)
+	lg2, _ := math.Lgamma(nu / 2)
+	return math.Exp(lg1 - lg2 - 0.5*math.Log(nu*math.Pi) - (nu+1)/2*math.Log1p(t*t/nu))
+}
+
+// tinv returns the inverse of the Student's t cumulative distribution,
+// the tail probability is inverted by Newton's method kept in a bracket
+func tinv(args []Value) (Value, error) {
+	xs, err := floatArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	nu := xs[1]
+	if nu <= 0 {
+		return nil, errPositive
+	}
+	p, q, err := probability(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if p == 0.5 {
+		return new(big.Float), nil
+	}
+	q = min(p, q)
+
+	lo, hi := 0.0, 1.0
+	for tTail(hi, nu) > q {
+		lo, hi = hi, hi*2
+	}
+	t := (lo + hi) / 2
+	for range distMaxIter {
+		f := tTail(t, nu) - q
+		if f > 0 {
+			lo = t
+		} else {
+			hi = t
+		}
+		next := t + f/tPDF(t, nu)
+		if next <= lo || next >= hi {
+			next = (lo + hi) / 2
+		}
+		if math.Abs(next-t) <= distEpsilon*8*math.Abs(t) {
+			t = next
+			break
+		}
+		t = next
+	}
+	if p < 0.5 {
+		t = -t
+	}
+	return big.NewFloat(t), nil
+}
+
+// chi2cdf returns the chi-square cumulative distribution chi2cdf(x, k)
+func chi2cdf(args []Value) (Value, error) {
+	xs, err := floatArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	x, k := xs[0], xs[1]
+	if k <= 0 {
+		return nil, errPositive
+	}
+	return big.NewFloat(gammaRegularized(k/2, x/2)), nil
+}
+
+// gammaRegularized returns the lower regularized incomplete gamma function
+// P(a, x) by its series or, for large x, by the continued fraction of Q
+func gammaRegularized(a, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	lg, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lg)
+	if x < a+1 {
+		sum, term := 1/a, 1/a
+		for n := 1; n < distMaxIter; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*distEpsilon {
+				break
+			}
+		}
+		return sum * prefix
+	}
+	return 1 - prefix*continuedFraction(func(n float64) (float64, float64) {
+		if n == 0 {
+			return 0, x + 1 - a
+		}
+		return -n * (n - a), x + 1 - a + 2*n
+	})
+}
+
+// betaRegularized returns the regularized incomplete beta function I_x(a, b)
+// by its continued fraction
+func betaRegularized(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	if x > (a+1)/(a+b+2) {
+		return 1 - betaRegularized(b, a, 1-x)
+	}
+	lab, _ := math.Lgamma(a + b)
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	prefix := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log1p(-x))
+	return prefix / a * continuedFraction(func(n float64) (float64, float64) {
+		if n == 0 {
+			return 0, 1
+		}
+		m := math.Floor((n + 1) / 2)
+		if int(n)%2 == 0 {
+			return m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m)), 1
+		}
+		return -(a + m - 1) * (a + b + m - 1) * x / ((a + 2*m - 2) * (a + 2*m - 1)), 1
+	})
+}
+
+// continuedFraction returns the reciprocal of b0 + a1/(b1 + a2/(b2 + ...))
+// evaluated by the modified Lentz's method, terms returns a_n and b_n
+func continuedFraction(terms func(n float64) (float64, float64)) float64 {
+	const tiny = 1e-300
+	_, b0 := terms(0)
+	f := b0
+	if f == 0 {
+		f = tiny
+	}
+	c, d := f, 0.0
+	for n := 1; n < distMaxIter; n++ {
+		an, bn := terms(float64(n))
+		d = bn + an*d
+		if d == 0 {
+			d = tiny
+		}
+		c = bn + an/c
+		if c == 0 {
+			c = tiny
+		}
+		d = 1 / d
+		delta := c * d
+		f *= delta
+		if math.Abs(delta-1) < distEpsilon {
+			break
+		}
+	}
+	return 1 / f
+}
+
+// countArgs converts the number of events k of the discrete distributions
+func countArgs(args []Value) ([]int64, error) {
+	ints, err := integerArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	counts := make([]int64, len(ints))
+	for i, n := range ints {
+		if n.Sign() < 0 {
+			return nil, errNegative
+		}
+		if n.Cmp(big.NewInt(maxTerms)) > 0 {
+			return nil, fmt.Errorf("the arguments must not be greater than %d", maxTerms)
+		}
+		counts[i] = n.Int64()
+	}
+	return counts, nil
+}
+
+// binomial returns the probability of k successes in n trials and the
+// probability of k or less, the terms are computed by their ratio
+func binomial(args []Value, stop func() bool) (pdf, cdf *big.Float, err error) {
+	counts, err := countArgs(args[:2])
+	if err != nil {
+		return nil, nil, err
+	}
+	k, n := counts[0], counts[1]
+	p, err := Real(args[2])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s argument not supported", typeName(args[2]))
+	}
+	one := big.NewFloat(1)
+	if p.Sign() < 0 || p.Cmp(one) > 0 {
+		return nil, nil, errProbability
+	}
+	zero := new(big.Float)
+	switch {
+	case k > n:
+		return zero, one, nil
+	case p.Sign() == 0 || p.Cmp(one) == 0:
+		// all the trials fail or succeed
+		certain := n
+		if p.Sign() == 0 {
+			certain = 0
+		}
+		pdf, cdf = zero, zero
+		if k == certain {
+			pdf = one
+		}
+		if k >= certain {
+			cdf = one
+		}
+		return pdf, cdf, nil
+	}
+
+	q := new(big.Float).SetPrec(floatPrec).Sub(one, p)
+	ratio := new(big.Float).SetPrec(floatPrec).Quo(p, q)
+	term := bigPowInt(q, n)
+	sum := new(big.Float).SetPrec(floatPrec).Set(term)
+	for i := range k {
+		if stop() {
+			return nil, nil, ErrDeadline
+		}
+		term.Mul(term, ratio)
+		term.Mul(term, new(big.Float).SetInt64(n-i))
+		term.Quo(term, new(big.Float).SetInt64(i+1))
+		sum.Add(sum, term)
+	}
+	return term, sum, nil
+}
+
+// binompdf returns the binomial probability binompdf(k, n, p)
+func binompdf(args []Value, stop func() bool) (Value, error) {
+	pdf, _, err := binomial(args, stop)
+	return pdf, err
+}
+
+// binomcdf returns the binomial cumulative probability binomcdf(k, n, p)
+func binomcdf(args []Value, stop func() bool) (Value, error) {
+	_, cdf, err := binomial(args, stop)
+	return cdf, err
+}
+
+// poisson returns the probability of k events with the mean lambda and
+// the probability of k or less
+func poisson(args []Value, stop func() bool) (pdf, cdf *big.Float, err error) {
+	counts, err := countArgs(args[:1])
+	if err != nil {
+		return nil, nil, err
+	}
+	lambda, err := Real(args[1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s argument not supported", typeName(args[1]))
+	}
+	if lambda.Sign() <= 0 {
+		return nil, nil, errPositive
+	}
+
+	term := bigExp(new(big.Float).Neg(lambda))
+	sum := new(big.Float).SetPrec(floatPrec).Set(term)
+	for i := range counts[0] {
+		if stop() {
+			return nil, nil, ErrDeadline
+		}
+		term.Mul(term, lambda)
+		term.Quo(term, new(big.Float).SetInt64(i+1))
+		sum.Add(sum, term)
+	}
+	return term, sum, nil
+}
+
+// bigExp returns e^x with the Taylor series of x/2^k squared k times
+func bigExp(x *big.Float) *big.Float {
+	const guardBits = 32
+	prec := uint(floatPrec + guardBits)
+	r := new(big.Float).SetPrec(prec).Set(x)
+	k := 0
+	for r.MantExp(nil) > -1 {
+		// |r| >= 0.5
+		r.Quo(r, big.NewFloat(2))
+		k++
+	}
+	sum := new(big.Float).SetPrec(prec).SetInt64(1)
+	term := new(big.Float).SetPrec(prec).SetInt64(1)
+	limit := new(big.Float).SetMantExp(big.NewFloat(1), -int(prec))
+	for n := int64(1); ; n++ {
+		term.Mul(term, r)
+		term.Quo(term, new(big.Float).SetInt64(n))
+		sum.Add(sum, term)
+		if new(big.Float).Abs(term).Cmp(limit) < 0 {
+			break
+		}
+	}
+	for range k {
+		sum.Mul(sum, sum)
+	}
+	return sum.SetPrec(floatPrec)
+}
+
+// poissonpdf returns the Poisson probability poissonpdf(k, lambda)
+func poissonpdf(args []Value, stop func() bool) (Value, error) {
+	pdf, _, err := poisson(args, stop)
+	return pdf, err
+}
+
+// poissoncdf returns the Poisson cumulative probability poissoncdf(k, lambda)
+func poissoncdf(args []Value, stop func() bool) (Value, error) {
+	_, cdf, err := poisson(args, stop)
+	return cdf, err
+}
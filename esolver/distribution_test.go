@@ -0,0 +1,116 @@
+package esolver
+
+import (
+	"math/big"
+	"testing"
+)
+
+// The references are tabulated values and closed forms: the t distribution
+// with 1, 2 and 10 degrees of freedom, the chi-square with an even number
+// of degrees and the exact sums of the discrete distributions
+func Test_esolver_Distribution(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"normcdf(0)", "0.5"},
+		{"normcdf(1)", "0.84134474606854293"},
+		{"normcdf(1.96)", "0.97500210485177952"},
+		{"normcdf(-3)", "0.0013498980316300946"},
+		{"normcdf(-6)", "9.8658764503769814e-10"},
+		{"normcdf(110, 100, 10)", "0.84134474606854293"},
+		{"norminv(0.5)", "0"},
+		{"norminv(0.95)", "1.6448536269514722"},
+		{"norminv(0.975)", "1.9599639845400543"},
+		{"norminv(0.999999)", "4.7534243088228989"},
+		{"norminv(10^-10)", "-6.3613409024040557"},
+		{"norminv(0.975, 100, 10)", "119.59963984540054"},
+		// 0.5 + atan(t)/pi
+		{"tcdf(3, 1)", "0.89758361765043327"},
+		{"tcdf(-10^10, 1)", "3.1830988618379067e-11"},
+		// 0.5 + t/(2*sqrt(2 + t^2))
+		{"tcdf(1, 2)", "0.78867513459481288"},
+		{"tcdf(2, 10)", "0.96330598261462990"},
+		{"tcdf(0, 5)", "0.5"},
+		{"tinv(0.975, 1)", "12.706204736174707"},
+		{"tinv(0.975, 2)", "4.3026527297494637"},
+		{"tinv(0.025, 10)", "-2.2281388519862735"},
+		{"tinv(0.5, 7)", "0"},
+		{"chi2cdf(3, 2)", "0.77686983985157017"},
+		{"chi2cdf(10, 4)", "0.95957231800548720"},
+		{"chi2cdf(0.5, 10)", "0.0000066117105610342470"},
+		{"chi2cdf(30, 20)", "0.93014633930059023"},
+		{"chi2cdf(-1, 3)", "0"},
+		{"binompdf(3, 10, 0.5)", "0.1171875"},
+		{"binomcdf(3, 10, 0.5)", "0.171875"},
+		{"binompdf(50, 100, 0.5)", "0.07958923738717876149812705024217046140293"},
+		{"binomcdf(50, 100, 0.5)", "0.5397946186935893807490635251210852307015"},
+		{"binompdf(2, 20, 0.1)", "0.2851798070642983299"},
+		{"binomcdf(2, 20, 0.1)", "0.67692680518946603571"},
+		{"binomcdf(7, 30, 0.25)", "0.5142899630836913101028518013890789006837"},
+		{"binompdf(990, 1000, 0.99)", "0.1257402111262073799345893673665168263661"},
+		{"binomcdf(990, 1000, 0.99)", "0.5426994078251091745515597586244106174183"},
+		{"binompdf(12, 10, 0.3)", "0"},
+		{"binomcdf(12, 10, 0.3)", "1"},
+		{"binompdf(0, 10, 0)", "1"},
+		{"binompdf(10, 10, 1)", "1"},
+		{"binomcdf(9, 10, 1)", "0"},
+		{"poissonpdf(2, 3)", "0.2240418076553877434070408704252779948426"},
+		{"poissoncdf(2, 3)", "0.4231900811268435153244105330255251013694"},
+		{"poissonpdf(0, 0.1)", "0.9048374180359595731642490594464366211947"},
+		{"poissoncdf(10, 4.5)", "0.9933313279128180664720497019165339391572"},
+		{"poissonpdf(1000, 1000)", "0.01261461134872149971803693647457875764716"},
+		{"poissoncdf(1000, 1000)", "0.5084093671685059912142590928724599584579"},
+		{"poissoncdf(1, 2000)", "5.155648281795260454032491149514213178357e-866"},
+	}
+	// at least 12 digits
+	tolerance := big.NewFloat(1e-12)
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := e.Solve(tt.expr)
+			if err != nil {
+				t.Fatalf("Solve() failed: %v", err)
+			}
+			want, _, _ := big.ParseFloat(tt.want, 10, floatPrec, big.ToNearestEven)
+			diff := new(big.Float).Sub(got, want)
+			diff.Abs(diff)
+			if want.Sign() != 0 {
+				diff.Quo(diff, new(big.Float).Abs(want))
+			}
+			if diff.Cmp(tolerance) > 0 {
+				t.Errorf("Solve() = %v, want %v", got.Text('g', 20), tt.want)
+			}
+		})
+	}
+}
+
+func Test_esolver_DistributionErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"normcdf(1, 2)", "normcdf: give both mu and sigma"},
+		{"normcdf(1, 0, -1)", "normcdf: sigma must be positive"},
+		{"norminv(0)", "norminv: the probability must be between 0 and 1"},
+		{"norminv(1)", "norminv: the probability must be between 0 and 1"},
+		{"tcdf(1, 0)", "tcdf: the parameter must be positive"},
+		{"tinv(1.5, 3)", "tinv: the probability must be between 0 and 1"},
+		{"chi2cdf(1, -2)", "chi2cdf: the parameter must be positive"},
+		{"binompdf(1.5, 10, 0.5)", "binompdf: the arguments must be integers"},
+		{"binompdf(-1, 10, 0.5)", "binompdf: the arguments must not be negative"},
+		{"binomcdf(1, 10, 1.5)", "binomcdf: the probability must be between 0 and 1"},
+		{"poissonpdf(1, 0)", "poissonpdf: the parameter must be positive"},
+		{"poissoncdf(2000000, 1)", "poissoncdf: the arguments must not be greater than 1000000"},
+		{"normcdf(2i)", "normcdf: complex argument not supported"},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := e.Eval(tt.expr)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Eval() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package esolver
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Doc documents a built-in function, constant or operator for the help, the
+// completion and the README
+type Doc struct {
+	Name string
+	// Topic groups the docs, like Statistics, the constants are in Constants
+	// and the operators in Operators
+	Topic string
+	// Signature names the arguments like pv(rate, nper, pmt, fv, type), the
+	// optional ones are last
+	Signature   string
+	Description string
+	// Domain tells the arguments accepted when they are limited, like x >= 0
+	Domain   string
+	Examples []string
+}
+
+// topics of the constants and the operators, the functions have the others
+const (
+	ConstantsTopic = "Constants"
+	OperatorsTopic = "Operators"
+)
+
+// fn documents a function named by its signature
+func fn(topic, signature, description, domain string, examples ...string) Doc {
+	name, _, _ := strings.Cut(signature, "(")
+	return Doc{name, topic, signature, description, domain, examples}
+}
+
+func constant(name, description string, examples ...string) Doc {
+	return Doc{name, ConstantsTopic, name, description, "", examples}
+}
+
+func operator(name, signature, description string, examples ...string) Doc {
+	return Doc{name, OperatorsTopic, signature, description, "", examples}
+}
+
+// docs are in the order of the help and the README
+var docs = []Doc{
+	fn("Math", "ln(x)", "natural logarithm", "x > 0", "ln(e^2)"),
+	fn("Math", "abs(x)", "absolute value or modulus of a complex number", "", "abs(-3)", "abs(3+4i)"),
+	fn("Math", "sin(angle)", "sine", "", "sin30"),
+	fn("Math", "cos(angle)", "cosine", "", "cos60"),
+	fn("Math", "tan(angle)", "tangent", "", "tan45"),
+	fn("Math", "asin(x)", "arc sine", "-1 <= x <= 1", "asin(0.5)"),
+	fn("Math", "acos(x)", "arc cosine", "-1 <= x <= 1", "acos(0.5)"),
+	fn("Math", "atan(x)", "arc tangent", "", "atan(1)"),
+	fn("Math", "sqrt(x)", "square root", "x >= 0, any x in complex mode", "sqrt(2)"),
+	fn("Math", "cbrt(x)", "cube root", "", "cbrt(-27)"),
+	fn("Math", "ceil(x)", "smallest integer not less than x", "", "ceil(2.1)"),
+	fn("Math", "floor(x)", "largest integer not greater than x", "", "floor(2.9)"),
+	fn("Complex", "arg(z)", "angle of a complex number", "", "arg(1+i)"),
+	fn("Complex", "conj(z)", "complex conjugate", "", "conj(3+4i)"),
+	fn("Complex", "re(z)", "real part", "", "re(3+4i)"),
+	fn("Complex", "im(z)", "imaginary part", "", "im(3+4i)"),
+	fn("Logic", "if(condition, then, else)", "then when the condition is true, otherwise else", "",
+		"if(2 > 1, 10, 20)"),
+	fn("Vectors and matrices", "dot(u, v)", "dot product of two vectors", "vectors of the same length",
+		"dot([1, 2, 3], [4, 5, 6])"),
+	fn("Vectors and matrices", "cross(u, v)", "cross product of two vectors of 3 elements", "vectors of 3 elements",
+		"cross([1, 0, 0], [0, 1, 0])"),
+	fn("Vectors and matrices", "norm(v)", "length of a vector", "", "norm([3, 4])"),
+	fn("Vectors and matrices", "det(m)", "determinant of a square matrix", "square matrices", "det([[1, 2], [3, 4]])"),
+	fn("Vectors and matrices", "inv(m)", "inverse of a square matrix", "square matrices with det(m) != 0",
+		"inv([[2, 0], [0, 4]])"),
+	fn("Vectors and matrices", "transpose(m)", "transposed matrix", "", "transpose([[1, 2], [3, 4]])"),
+	fn("Calculus", "solve(equation, x, guess)", "root of an equation or solution of a linear system solve(a, b)",
+		"the variable and the guess are optional", "solve(x^3 - 2x - 5 = 0, x, 2)", "solve([[2, 1], [1, 3]], [3, 5])"),
+	fn("Calculus", "integrate(f, x, a, b)", "definite integral of f from a to b", "", "integrate(x^2, x, 0, 3)"),
+	fn("Calculus", "deriv(f, x, a)", "derivative of f at x = a", "", "deriv(x^3, x, 2)"),
+	fn("Calculus", "diff(f, x)", "derivative of f as an expression", "the variable is optional", "diff(x^2*sin(x), x)"),
+	fn("Statistics", "sum(values)", "sum of the values", "", "sum({1, 2, 3})"),
+	fn("Statistics", "mean(values)", "arithmetic mean", "", "mean({1, 2, 3, 4})"),
+	fn("Statistics", "median(values)", "middle value", "", "median({3, 1, 2})"),
+	fn("Statistics", "mode(values)", "most frequent value", "", "mode({1, 2, 2, 3})"),
+	fn("Statistics", "stdev(values)", "sample standard deviation", "at least two values", "stdev({2, 4, 4, 4, 5, 5, 7, 9})"),
+	fn("Statistics", "var(values)", "sample variance", "at least two values", "var({1, 2, 3, 4})"),
+	fn("Statistics", "min(values)", "smallest value", "", "min({4, 2, 8})"),
+	fn("Statistics", "max(values)", "largest value", "", "max(4, 2, 8)"),
+	fn("Statistics", "percentile(values, p)", "value below which p percent of the values fall", "0 <= p <= 100",
+		"percentile({1, 2, 3, 4}, 90)"),
+	fn("Statistics", "linreg(x, y)", "least squares line [slope, intercept, r²]", "lists of the same length",
+		"linreg({1, 2, 3}, {2, 4, 6.5})"),
+	fn("Integers", "factorial(n)", "exact factorial n!", "integers n >= 0", "factorial(20)"),
+	fn("Integers", "ncr(n, r)", "combinations of r items out of n", "integers 0 <= r <= n", "ncr(5, 2)"),
+	fn("Integers", "npr(n, r)", "permutations of r items out of n", "integers 0 <= r <= n", "npr(5, 2)"),
+	fn("Integers", "isprime(n)", "whether n is a prime number", "integers", "isprime(97)"),
+	fn("Integers", "factor(n)", "prime factors like 2^3 * 3^2 * 5", "integers n >= 2", "factor(360)"),
+	fn("Integers", "gcd(a, b)", "greatest common divisor", "integers", "gcd(12, 18)"),
+	fn("Integers", "lcm(a, b)", "least common multiple", "integers", "lcm(4, 6)"),
+	fn("Integers", "powmod(b, e, m)", "b^e modulo m", "integers e >= 0 and m > 0", "powmod(2, 100, 1000000007)"),
+	fn("Distributions", "normcdf(x, mu, sigma)", "normal cumulative distribution", "sigma > 0, mu and sigma are optional",
+		"normcdf(1.96)"),
+	fn("Distributions", "norminv(p, mu, sigma)", "inverse of the normal cumulative distribution", "0 < p < 1",
+		"norminv(0.975)"),
+	fn("Distributions", "tcdf(t, nu)", "Student's t cumulative distribution", "nu > 0", "tcdf(2, 10)"),
+	fn("Distributions", "tinv(p, nu)", "inverse of the Student's t cumulative distribution", "0 < p < 1 and nu > 0",
+		"tinv(0.975, 10)"),
+	fn("Distributions", "chi2cdf(x, k)", "chi-square cumulative distribution", "k > 0", "chi2cdf(3.84, 1)"),
+	fn("Distributions", "binompdf(k, n, p)", "probability of k successes in n trials", "0 <= p <= 1", "binompdf(3, 10, 0.5)"),
+	fn("Distributions", "binomcdf(k, n, p)", "probability of k or less successes in n trials", "0 <= p <= 1",
+		"binomcdf(3, 10, 0.5)"),
+	fn("Distributions", "poissonpdf(k, lambda)", "probability of k events with the mean lambda", "lambda > 0",
+		"poissonpdf(2, 3)"),
+	fn("Distributions", "poissoncdf(k, lambda)", "probability of k or less events with the mean lambda", "lambda > 0",
+		"poissoncdf(2, 3)"),
+	fn("Finance", "pv(rate, nper, pmt, fv, type)", "present value", "fv and type are optional, type 1 pays at the start",
+		"pv(0.05/12, 360, -1000)"),
+	fn("Finance", "fv(rate, nper, pmt, pv, type)", "future value", "pv and type are optional", "fv(0.04, 10, -100)"),
+	fn("Finance", "pmt(rate, nper, pv, fv, type)", "payment per period", "fv and type are optional",
+		"pmt(0.05/12, 360, 200000)"),
+	fn("Finance", "nper(rate, pmt, pv, fv, type)", "number of periods", "fv and type are optional", "nper(0.01, -100, 1000)"),
+	fn("Finance", "rate(nper, pmt, pv, fv, type, guess)", "interest rate per period", "fv, type and guess are optional",
+		"rate(10, -100, 800)"),
+	fn("Finance", "npv(rate, values)", "net present value of the cash flows", "", "npv(0.1, -1000, 300, 400, 500)"),
+	fn("Finance", "irr(values, guess)", "internal rate of return", "the guess is optional", "irr({-1000, 300, 400, 500})"),
+	fn("Finance", "simpleint(principal, rate, nper)", "simple interest", "", "simpleint(1000, 0.05, 3)"),
+	fn("Finance", "compoundint(principal, rate, nper, m)", "compound interest with m compoundings per period",
+		"m is optional", "compoundint(1000, 0.05, 3, 12)"),
+	fn("Dates and times", "days(hours)", "days of a duration", "", "days(2026-12-25 - 2026-10-18)"),
+	fn("Surveying", "bearing(azimuth)", "quadrant bearing of an azimuth", "", "bearing(135.5)"),
+	fn("Surveying", "azimuth(angle)", "angle between 0 and 360 degrees", "", "azimuth(-45)"),
+	fn("Surveying", "latdep(azimuth, distance)", "[latitude, departure] of a traverse line", "", "latdep(N45dE, 100)"),
+	fn("Surveying", "inverse([n1, e1], [n2, e2])", "distance and bearing from the first point to the second", "",
+		"inverse([0, 0], [100, 100])"),
+
+	constant("e", "Euler's number", "e^2"),
+	constant("pi", "ratio of the circumference of a circle to its diameter", "2*pi*3"),
+	constant("phi", "golden ratio", "2*phi"),
+	constant("sqrtii", "square root of 2", "10*sqrtii"),
+	constant("sqrte", "square root of e"),
+	constant("sqrtpi", "square root of pi"),
+	constant("sqrtphi", "square root of phi"),
+	constant("in", "millimeters in an inch", "3/8 in"),
+	constant("i", "imaginary unit", "3+4i", "i^2"),
+	constant("j", "imaginary unit like i", "10 + 5j"),
+	constant("true", "boolean true", "true and 2 > 1"),
+	constant("false", "boolean false", "not false"),
+	constant("today", "date of today", "today + 90d"),
+	constant("now", "date and time of now", "now + 2h"),
+	constant("ans", "last answer, used for the missing operand of *2 or sin", "ans*2"),
+
+	operator("+", "a + b", "addition, dates plus durations", "2 + 3", "2026-10-18 + 90d"),
+	operator("-", "a - b", "subtraction or negation, the durations between dates", "5 - 3", "2 * -3"),
+	operator("*", "a * b", "multiplication, matrix product", "2 * 3", "[[1, 2], [3, 4]] * [1, 1]"),
+	operator("/", "a / b", "division", "7 / 2"),
+	operator("^", "a ^ b", "power, right associative", "2^3^2"),
+	operator("∠", "r ∠ angle", "complex number of the modulus r and the angle, also written @", "10∠30", "10@30"),
+	operator(".*", "u .* v", "element by element multiplication", "[1, 2] .* [3, 4]"),
+	operator("./", "u ./ v", "element by element division", "[4, 9] ./ [2, 3]"),
+	operator(".^", "u .^ n", "element by element power", "[1, 2, 3] .^ 2"),
+	operator("<", "a < b", "less than", "1 < 2"),
+	operator("<=", "a <= b", "less than or equal", "2 <= 2"),
+	operator(">", "a > b", "greater than", "3 > 2"),
+	operator(">=", "a >= b", "greater than or equal", "2 >= 3"),
+	operator("==", "a == b", "equal", "0.1 + 0.2 == 0.3"),
+	operator("!=", "a != b", "not equal", "1 != 2"),
+	operator("and", "p and q", "true when both are true", "1 < 2 and 2 < 3"),
+	operator("or", "p or q", "true when any is true", "1 > 2 or 2 < 3"),
+	operator("not", "not p", "true when p is false", "not 1 > 2"),
+	operator("=", "name = expression", "assigns a variable, in solve it is the equation", "sigma = 150"),
+}
+
+// docIndex finds the docs by their names
+var docIndex = func() map[string]int {
+	index := make(map[string]int, len(docs))
+	for i, d := range docs {
+		index[d.Name] = i
+	}
+	return index
+}()
+
+// Describe returns the documentation of a built-in function, constant or
+// operator
+func Describe(name string) (Doc, bool) {
+	i, ok := docIndex[name]
+	if !ok {
+		return Doc{}, false
+	}
+	return docs[i], true
+}
+
+// Docs returns the documentation of the built-in functions, constants and
+// operators grouped by topic
+func Docs() []Doc {
+	return slices.Clone(docs)
+}
+
+// Matches tells whether the word is in the name, the signature, the topic
+// or the description ignoring the case
+func (d Doc) Matches(word string) bool {
+	word = strings.ToLower(word)
+	for _, s := range []string{d.Name, d.Signature, d.Topic, d.Description} {
+		if strings.Contains(strings.ToLower(s), word) {
+			return true
+		}
+	}
+	return false
+}
+
+// Names returns the names of the functions, the constants and the
+// variables known by the solver, including the ones added by AddValue and
+// AddFunction
+func (e *esolver) Names() map[string]TokenType {
+	return maps.Clone(e.elemNames)
+}
@@ -0,0 +1,50 @@
+package esolver
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	var names []string
+	for name := range funcs {
+		names = append(names, name)
+	}
+	for name := range valueFuncs {
+		names = append(names, name)
+	}
+	for name := range New().Names() {
+		names = append(names, name)
+	}
+	for name := range oprData {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		d, ok := Describe(name)
+		if !ok || d.Name != name || d.Description == "" {
+			t.Errorf("Describe(%q) = %v, %v", name, d, ok)
+		}
+		if _, function := valueFuncs[name]; (function || funcs[name] != nil) && !strings.HasPrefix(d.Signature, name+"(") {
+			t.Errorf("Describe(%q).Signature = %q", name, d.Signature)
+		}
+	}
+	if len(docIndex) != len(docs) {
+		t.Errorf("%d docs have the same name", len(docs)-len(docIndex))
+	}
+	if _, ok := Describe("x"); ok {
+		t.Error("Describe(x) documented a variable")
+	}
+}
+
+func TestDocMatches(t *testing.T) {
+	var got []string
+	for _, d := range Docs() {
+		if d.Matches("Interest") {
+			got = append(got, d.Name)
+		}
+	}
+	if want := []string{"rate", "simpleint", "compoundint"}; !slices.Equal(got, want) {
+		t.Errorf("Matches(Interest) = %v, want %v", got, want)
+	}
+}
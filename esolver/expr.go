@@ -0,0 +1,102 @@
+package esolver
+
+import (
+	"errors"
+	"maps"
+	"math/big"
+)
+
+var errEquation = errors.New("equations are only allowed in solve")
+
+// Expr is an argument of a lazy function compiled to postfix, the function
+// evaluates it as many times as needed binding its own variables
+type Expr struct {
+	postfix Stack
+	solver  *esolver
+	scope   map[string]Value
+}
+
+// Eval evaluates the expression, vars shadow the constants and the
+// variables of the enclosing expressions with the same name
+func (x *Expr) Eval(vars map[string]Value) (Value, error) {
+	scope := maps.Clone(x.scope)
+	if scope == nil {
+		scope = make(map[string]Value, len(vars))
+	}
+	maps.Copy(scope, vars)
+	return x.solver.evalPostfix(x.postfix, scope)
+}
+
+// Name returns the name when the expression is a single identifier
+func (x *Expr) Name() (string, bool) {
+	if len(x.postfix.Values) != 1 {
+		return "", false
+	}
+	t := x.postfix.Values[0]
+	return t.Value, t.Type == VARIABLE || t.Type == CONSTANT
+}
+
+// Unknowns lists the names without value in the expression
+func (x *Expr) Unknowns() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, t := range x.postfix.Values {
+		if t.Type != VARIABLE || seen[t.Value] {
+			continue
+		}
+		if _, ok := x.scope[t.Value]; ok {
+			continue
+		}
+		if _, ok := x.solver.findConst(t.Value); ok {
+			continue
+		}
+		seen[t.Value] = true
+		names = append(names, t.Value)
+	}
+	return names
+}
+
+// variableName returns the variable named by the first of args or, when it
+// is omitted, the single unknown of the expression
+func variableName(x *Expr, args []Value) (string, error) {
+	if len(args) > 0 {
+		name, ok := args[0].(*Expr).Name()
+		if !ok {
+			return "", errors.New("the second argument must be the variable")
+		}
+		return name, nil
+	}
+	if unknowns := x.Unknowns(); len(unknowns) == 1 {
+		return unknowns[0], nil
+	}
+	return "", errors.New("specify the variable as the second argument")
+}
+
+// equation returns the expression lhs-rhs for an equation lhs=rhs
+func (x *Expr) equation() *Expr {
+	n := len(x.postfix.Values)
+	if n == 0 || x.postfix.Values[n-1] != (Token{Type: OPERATOR, Value: "="}) {
+		return x
+	}
+	values := append([]Token(nil), x.postfix.Values...)
+	values[n-1] = Token{Type: OPERATOR, Value: "-"}
+	return &Expr{postfix: Stack{values}, solver: x.solver, scope: x.scope}
+}
+
+// operand unwraps values that carry extra information about the result
+// when they are used in another operation
+func operand(v Value) Value {
+	switch x := v.(type) {
+	case *Root:
+		return x.X
+	case *Estimate:
+		return x.X
+	case *big.Int:
+		return intToFloat(x)
+	case *Factors:
+		return intToFloat(x.N)
+	case *Bearing:
+		return x.Azimuth
+	}
+	return v
+}
@@ -0,0 +1,298 @@
+package esolver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// financeMaxIter limits the Newton's iterations of rate and irr
+const financeMaxIter = 100
+
+// financeTolerance is the change of the rate that ends the iterations
+var financeTolerance = new(big.Float).SetMantExp(big.NewFloat(1), -200)
+
+var errType = errors.New("type must be 0 for payments at the end or 1 at the beginning of the periods")
+
+// reals converts the arguments, the omitted optional ones are zero
+func reals(args []Value, n int) ([]*big.Float, error) {
+	xs := make([]*big.Float, n)
+	for i := range xs {
+		if i >= len(args) {
+			xs[i] = new(big.Float)
+			continue
+		}
+		x, err := Real(args[i])
+		if err != nil {
+			return nil, fmt.Errorf("%s argument not supported", typeName(args[i]))
+		}
+		xs[i] = x
+	}
+	return xs, nil
+}
+
+func newFloat() *big.Float {
+	return new(big.Float).SetPrec(floatPrec)
+}
+
+// paymentType returns 1 + rate*type, the growth of the payments made at
+// the beginning of the periods
+func paymentType(rate, typ *big.Float) (*big.Float, error) {
+	switch {
+	case typ.Sign() == 0:
+		return big.NewFloat(1), nil
+	case typ.Cmp(big.NewFloat(1)) == 0:
+		return newFloat().Add(big.NewFloat(1), rate), nil
+	}
+	return nil, errType
+}
+
+// annuity returns the growth (1 + rate)^nper and the future value of the
+// payments of 1, ((1 + rate)^nper - 1)/rate, which is nper for a zero rate
+func annuity(rate, nper *big.Float) (growth, annuity *big.Float) {
+	if rate.Sign() == 0 {
+		return big.NewFloat(1), nper
+	}
+	growth = bigPow(newFloat().Add(big.NewFloat(1), rate), nper)
+	annuity = newFloat().Sub(growth, big.NewFloat(1))
+	return growth, annuity.Quo(annuity, rate)
+}
+
+// tvm returns the terms of pv*growth + pmt*due*annuity + fv, which is zero
+// for the values of a loan or an investment
+func tvm(rate, nper, typ *big.Float) (growth, payments *big.Float, err error) {
+	due, err := paymentType(rate, typ)
+	if err != nil {
+		return nil, nil, err
+	}
+	growth, a := annuity(rate, nper)
+	return growth, newFloat().Mul(due, a), nil
+}
+
+// pv returns the present value pv(rate, nper, pmt, fv, type)
+func pv(args []Value) (Value, error) {
+	x, err := reals(args, 5)
+	if err != nil {
+		return nil, err
+	}
+	rate, nper, pmt, fv, typ := x[0], x[1], x[2], x[3], x[4]
+	growth, payments, err := tvm(rate, nper, typ)
+	if err != nil {
+		return nil, err
+	}
+	v := newFloat().Mul(pmt, payments)
+	v.Add(v, fv)
+	return v.Neg(v.Quo(v, growth)), nil
+}
+
+// fv returns the future value fv(rate, nper, pmt, pv, type)
+func fv(args []Value) (Value, error) {
+	x, err := reals(args, 5)
+	if err != nil {
+		return nil, err
+	}
+	rate, nper, pmt, pv, typ := x[0], x[1], x[2], x[3], x[4]
+	growth, payments, err := tvm(rate, nper, typ)
+	if err != nil {
+		return nil, err
+	}
+	v := newFloat().Mul(pmt, payments)
+	v.Add(v, newFloat().Mul(pv, growth))
+	return v.Neg(v), nil
+}
+
+// pmt returns the payment of each period pmt(rate, nper, pv, fv, type)
+func pmt(args []Value) (Value, error) {
+	x, err := reals(args, 5)
+	if err != nil {
+		return nil, err
+	}
+	rate, nper, pv, fv, typ := x[0], x[1], x[2], x[3], x[4]
+	growth, payments, err := tvm(rate, nper, typ)
+	if err != nil {
+		return nil, err
+	}
+	if payments.Sign() == 0 {
+		return nil, errors.New("the number of periods must not be zero")
+	}
+	v := newFloat().Mul(pv, growth)
+	v.Add(v, fv)
+	return v.Neg(v.Quo(v, payments)), nil
+}
+
+// nper returns the number of periods nper(rate, pmt, pv, fv, type)
+func nper(args []Value) (Value, error) {
+	x, err := reals(args, 5)
+	if err != nil {
+		return nil, err
+	}
+	rate, pmt, pv, fv, typ := x[0], x[1], x[2], x[3], x[4]
+	if rate.Sign() == 0 {
+		if pmt.Sign() == 0 {
+			return nil, errors.New("the payment must not be zero")
+		}
+		v := newFloat().Add(pv, fv)
+		return v.Neg(v.Quo(v, pmt)), nil
+	}
+	due, err := paymentType(rate, typ)
+	if err != nil {
+		return nil, err
+	}
+	// (1 + rate)^nper = (pmt*due - fv*rate)/(pmt*due + pv*rate)
+	payment := newFloat().Mul(pmt, due)
+	num := newFloat().Sub(payment, newFloat().Mul(fv, rate))
+	den := newFloat().Add(payment, newFloat().Mul(pv, rate))
+	if den.Sign() == 0 || num.Sign()*den.Sign() <= 0 {
+		return nil, errors.New("no number of periods reaches the future value")
+	}
+	ratio, _ := num.Quo(num, den).Float64()
+	r, _ := rate.Float64()
+	return big.NewFloat(math.Log(ratio) / math.Log1p(r)), nil
+}
+
+// rate returns the interest rate of each period rate(nper, pmt, pv, fv,
+// type, guess) solving the value equation by Newton's method
+func rate(args []Value) (Value, error) {
+	x, err := reals(args, 6)
+	if err != nil {
+		return nil, err
+	}
+	nper, pmt, pv, fv, typ, guess := x[0], x[1], x[2], x[3], x[4], x[5]
+	if typ.Sign() != 0 && typ.Cmp(big.NewFloat(1)) != 0 {
+		return nil, errType
+	}
+	if len(args) < 6 {
+		guess = big.NewFloat(0.1)
+	}
+
+	f := func(r *big.Float) (*big.Float, error) {
+		growth, payments, err := tvm(r, nper, typ)
+		if err != nil {
+			return nil, err
+		}
+		v := newFloat().Mul(pv, growth)
+		v.Add(v, newFloat().Mul(pmt, payments))
+		return v.Add(v, fv), nil
+	}
+	return newton(f, guess)
+}
+
+// npv returns the net present value npv(rate, values...) of the values at
+// the end of the periods 1, 2, ...
+func npv(args []Value) (Value, error) {
+	x, err := reals(args[:1], 1)
+	if err != nil {
+		return nil, err
+	}
+	values, err := samples(args[1:])
+	if err != nil {
+		return nil, err
+	}
+	return presentValue(x[0], values, 1), nil
+}
+
+// presentValue discounts the values, the first one at the period first
+func presentValue(rate *big.Float, values []*big.Float, first int64) *big.Float {
+	factor := newFloat().Add(big.NewFloat(1), rate)
+	discount := bigPowInt(factor, -first)
+	sum := newFloat()
+	for _, v := range values {
+		sum.Add(sum, newFloat().Mul(v, discount))
+		discount.Quo(discount, factor)
+	}
+	return sum
+}
+
+// irr returns the internal rate of return irr(values, guess) of the values
+// at the periods 0, 1, ... where the net present value is zero
+func irr(args []Value) (Value, error) {
+	values, err := samples(args[:1])
+	if err != nil {
+		return nil, err
+	}
+	var positive, negative bool
+	for _, v := range values {
+		positive = positive || v.Sign() > 0
+		negative = negative || v.Sign() < 0
+	}
+	if !positive || !negative {
+		return nil, errors.New("the values need a positive and a negative cash flow")
+	}
+	guess := big.NewFloat(0.1)
+	if len(args) > 1 {
+		x, err := reals(args[1:], 1)
+		if err != nil {
+			return nil, err
+		}
+		guess = x[0]
+	}
+	return newton(func(r *big.Float) (*big.Float, error) {
+		return presentValue(r, values, 0), nil
+	}, guess)
+}
+
+// newton finds the rate where f is zero, the derivative is estimated by a
+// central difference
+func newton(f func(r *big.Float) (*big.Float, error), guess *big.Float) (Value, error) {
+	minRate := big.NewFloat(-1)
+	h := new(big.Float).SetMantExp(big.NewFloat(1), -80)
+	r := newFloat().Set(guess)
+	for range financeMaxIter {
+		if r.Cmp(minRate) <= 0 {
+			break
+		}
+		y, err := f(r)
+		if err != nil {
+			return nil, err
+		}
+		y1, err := f(newFloat().Add(r, h))
+		if err != nil {
+			return nil, err
+		}
+		y0, err := f(newFloat().Sub(r, h))
+		if err != nil {
+			return nil, err
+		}
+		slope := newFloat().Sub(y1, y0)
+		if slope.Sign() == 0 {
+			break
+		}
+		slope.Quo(slope, newFloat().Mul(big.NewFloat(2), h))
+		step := newFloat().Quo(y, slope)
+		r.Sub(r, step)
+		if step.Abs(step).Cmp(financeTolerance) <= 0 {
+			return r, nil
+		}
+	}
+	return nil, errors.New("the rate did not converge, try another guess")
+}
+
+// simpleInterest returns the interest simpleint(principal, rate, nper)
+func simpleInterest(args []Value) (Value, error) {
+	x, err := reals(args, 3)
+	if err != nil {
+		return nil, err
+	}
+	v := newFloat().Mul(x[0], x[1])
+	return v.Mul(v, x[2]), nil
+}
+
+// compoundInterest returns the interest compoundint(principal, rate, nper,
+// m) compounded m times in each period, once when m is omitted
+func compoundInterest(args []Value) (Value, error) {
+	x, err := reals(args, 4)
+	if err != nil {
+		return nil, err
+	}
+	principal, rate, nper, m := x[0], x[1], x[2], x[3]
+	if len(args) < 4 {
+		m = big.NewFloat(1)
+	}
+	if m.Sign() <= 0 {
+		return nil, errors.New("the compoundings must be positive")
+	}
+	growth, _ := annuity(newFloat().Quo(rate, m), newFloat().Mul(nper, m))
+	v := newFloat().Sub(growth, big.NewFloat(1))
+	return v.Mul(v, principal), nil
+}
@@ -0,0 +1,81 @@
+package esolver
+
+import (
+	"math/big"
+	"testing"
+)
+
+// The references are the closed forms and the roots found by bisection
+// with 50 digits, the signs follow the spreadsheets: money paid is negative
+func Test_esolver_Finance(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"pv(0.05/12, 360, -1000)", "186281.6170460755271716218192744292695731"},
+		{"pv(0, 10, -100)", "1000"},
+		{"pv(0.1, 2, 0, -121)", "100"},
+		{"fv(0.06/12, 120, -100, -1000)", "18207.33141467857786293980556408380269736"},
+		{"fv(0.1, 2, 0, -100)", "121"},
+		{"fv(0.1, 1, -100, 0, 1)", "110"},
+		{"pmt(0.08/12, 60, 20000)", "-405.5278857682736493784935493935460290686"},
+		{"pmt(0.08/12, 60, 20000, 0, 1)", "-402.8422706307354132898942543644496977501"},
+		{"pmt(0, 10, 1000)", "-100"},
+		{"nper(0.01, -100, 1000)", "10.58864445942323599518820526881971345348"},
+		{"nper(0, -100, 1000)", "10"},
+		{"rate(60, -400, 20000)", "0.0061834131612539633250617507359866419138868035606105"},
+		{"rate(2, 0, -100, 121)", "0.1"},
+		{"npv(0.1, -10000, 3000, 4200, 6800)", "1188.443412335223003893176695580902943787"},
+		{"npv(0.1, {-10000, 3000}, {4200, 6800})", "1188.443412335223003893176695580902943787"},
+		{"irr({-70000, 12000, 15000, 18000, 21000, 26000})", "0.08663094803653161429309420250847771946562099647185"},
+		{"irr({-70000, 12000, 15000, 18000, 21000}, -0.1)", "-0.021244848273410991031050224838572897470041112911455"},
+		{"simpleint(1000, 0.05, 3)", "150"},
+		{"compoundint(1000, 0.05, 3)", "157.625"},
+		{"compoundint(1000, 0.12, 1, 12)", "126.825030131969720661201"},
+	}
+	// the computations keep at least 30 digits but nper uses float64 logarithms
+	tolerance := big.NewFloat(1e-14)
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := e.Solve(tt.expr)
+			if err != nil {
+				t.Fatalf("Solve() failed: %v", err)
+			}
+			want, _, _ := big.ParseFloat(tt.want, 10, floatPrec, big.ToNearestEven)
+			diff := new(big.Float).Sub(got, want)
+			diff.Abs(diff)
+			if want.Sign() != 0 {
+				diff.Quo(diff, new(big.Float).Abs(want))
+			}
+			if diff.Cmp(tolerance) > 0 {
+				t.Errorf("Solve() = %v, want %v", got.Text('g', 30), tt.want)
+			}
+		})
+	}
+}
+
+func Test_esolver_FinanceErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"pmt(0.1, 10, 1000, 0, 2)", "pmt: type must be 0 for payments at the end or 1 at the beginning of the periods"},
+		{"pmt(0.1, 0, 1000)", "pmt: the number of periods must not be zero"},
+		{"nper(0, 0, 1000)", "nper: the payment must not be zero"},
+		{"nper(0.1, -50, 1000)", "nper: no number of periods reaches the future value"},
+		{"irr({1, 2})", "irr: the values need a positive and a negative cash flow"},
+		{"compoundint(1000, 0.1, 1, 0)", "compoundint: the compoundings must be positive"},
+		{"pv(2i, 10, 100)", "pv: complex argument not supported"},
+		{"rate(10)", "rate: wrong number of arguments"},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := e.Eval(tt.expr)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Eval() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
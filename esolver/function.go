@@ -14,6 +14,16 @@ const (
 type Function func(*big.Float) *big.Float
 type ConstFunction func() *big.Float
 
+// ValueFunction is a function of one or more values of any kind, MaxArgs
+// lower than zero accepts any number of arguments. Lazy functions receive
+// every argument as *Expr to evaluate them with their own variables
+type ValueFunction struct {
+	MinArgs int
+	MaxArgs int
+	Fx      func(args []Value) (Value, error)
+	Lazy    bool
+}
+
 func sin(x *big.Float) *big.Float {
 	xf, _ := x.Float64()
 	return big.NewFloat(math.Sin(xf * degToRad))
@@ -78,23 +88,90 @@ func degToDec(value string) *big.Float {
 	return dms(d, m, s)
 }
 
+// timeUnits are the hours, as a fraction, of the time markers and the unit
+// of the number following them without a marker, so 2h30 is 2h 30m
+var timeUnits = map[byte]struct {
+	hours, per float64
+	next       byte
+}{
+	'd':  {24, 1, 'h'},
+	'h':  {1, 1, 'm'},
+	'm':  {1, 60, 's'},
+	'\'': {1, 60, 's'},
+	's':  {1, 3600, 's'},
+	'"':  {1, 3600, 's'},
+}
+
+// timeToDec converts a time like 1d 2h 30m 15s to hours, a number without
+// markers is in hours
+func timeToDec(value string) *big.Float {
+	hours := new(big.Float).SetPrec(floatPrec)
+	unit := byte('h')
+	for value != "" {
+		number, marker := value, unit
+		i := strings.IndexAny(value, `dhm's"`)
+		if i != -1 {
+			number, marker = value[:i], value[i]
+			value = value[i+1:]
+		} else {
+			value = ""
+		}
+		x, _, err := big.ParseFloat(strings.TrimSpace(number), 10, floatPrec, big.ToNearestEven)
+		if err != nil {
+			continue
+		}
+		u := timeUnits[marker]
+		x.Mul(x, big.NewFloat(u.hours))
+		hours.Add(hours, x.Quo(x, big.NewFloat(u.per)))
+		unit = u.next
+	}
+	return hours
+}
+
 func bigPow(x, y *big.Float) *big.Float {
+	if y.IsInt() {
+		if n, acc := y.Int64(); acc == big.Exact && n >= -1024 && n <= 1024 {
+			return bigPowInt(x, n)
+		}
+	}
 	xf, _ := x.Float64()
 	yf, _ := y.Float64()
 	return big.NewFloat(math.Pow(xf, yf))
 }
 
+// bigPowInt raises x to an integer power by repeated squaring keeping the
+// full precision
+func bigPowInt(x *big.Float, n int64) *big.Float {
+	prec := max(x.Prec(), floatPrec)
+	result := new(big.Float).SetPrec(prec).SetInt64(1)
+	base := new(big.Float).SetPrec(prec).Set(x)
+	negative := n < 0
+	if negative {
+		n = -n
+	}
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result.Mul(result, base)
+		}
+		base.Mul(base, base)
+	}
+	if negative {
+		return result.Quo(new(big.Float).SetPrec(prec).SetInt64(1), result)
+	}
+	return result
+}
+
 func bigLog(x *big.Float) *big.Float {
 	xf, _ := x.Float64()
 	return big.NewFloat(math.Log(xf))
 }
 
 func bigAbs(x *big.Float) *big.Float {
-	return x.Abs(x)
+	return new(big.Float).Abs(x)
 }
 
 func bigSqrt(x *big.Float) *big.Float {
-	return x.Sqrt(x)
+	return new(big.Float).Sqrt(x)
 }
 
 func bigCbrt(x *big.Float) *big.Float {
@@ -111,3 +188,37 @@ func bigFloor(x *big.Float) *big.Float {
 	xf, _ := x.Float64()
 	return big.NewFloat(math.Floor(xf))
 }
+
+func bigArg(x *big.Float) *big.Float {
+	if x.Sign() < 0 {
+		return big.NewFloat(180)
+	}
+	return big.NewFloat(0)
+}
+
+func bigRe(x *big.Float) *big.Float {
+	return x
+}
+
+func bigIm(x *big.Float) *big.Float {
+	return new(big.Float)
+}
+
+func bigPowReal(x, y *big.Float) (Value, error) {
+	if x.Sign() < 0 && !y.IsInt() {
+		return nil, errDomain
+	}
+	return bigPow(x, y), nil
+}
+
+func isUnit(x *big.Float) bool {
+	return new(big.Float).Abs(x).Cmp(big.NewFloat(1)) <= 0
+}
+
+// realDomain reports if a function result is real for the argument
+var realDomain = map[string]func(x *big.Float) bool{
+	"ln":   func(x *big.Float) bool { return x.Sign() >= 0 },
+	"sqrt": func(x *big.Float) bool { return x.Sign() >= 0 },
+	"acos": isUnit,
+	"asin": isUnit,
+}
@@ -0,0 +1,406 @@
+package esolver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// maxFactorial limits the factorials and permutations, 10000! has 35660
+// digits
+const maxFactorial = 10000
+
+// maxExactBits limits the size of the results of + - * and ^ computed
+// exactly when the operands are integers, larger ones are rounded
+const maxExactBits = 1 << 17
+
+// integerTolerance is the relative tolerance of the arguments rounded near
+// an integer, integerGuardBits are the bits of their precision that may be
+// rounded
+var integerTolerance = new(big.Float).SetMantExp(big.NewFloat(1), integerGuardBits-floatPrec)
+
+const integerGuardBits = 16
+
+// maxFloat64Bits are the bits of the integers of a float64
+const maxFloat64Bits = 53
+
+// maxModularBits limits the integers of isprime and factor and the modulus
+// and the exponent of powmod, their time grows with the cube of the bits
+const maxModularBits = 1 << 13
+
+// maxRhoSteps limits the steps of the Pollard's rho search of a factor
+const maxRhoSteps = 1 << 20
+
+var (
+	errNotInteger = errors.New("the arguments must be integers")
+	errNegative   = errors.New("the arguments must not be negative")
+	errRounded    = errors.New("the arguments must be exact integers, they were rounded")
+)
+
+// Factors is the prime factorization of N returned by factor, it is used
+// as the number N in the following operations
+type Factors struct {
+	N      *big.Int
+	Primes []*big.Int
+	Powers []int
+}
+
+// String writes the factorization as 2^3 * 3 * 5
+func (f *Factors) String() string {
+	terms := make([]string, len(f.Primes))
+	for i, p := range f.Primes {
+		terms[i] = p.String()
+		if f.Powers[i] > 1 {
+			terms[i] += fmt.Sprintf("^%d", f.Powers[i])
+		}
+	}
+	return strings.Join(terms, " * ")
+}
+
+// intToFloat converts exactly an integer, keeping at least the precision
+// of the computed values
+func intToFloat(n *big.Int) *big.Float {
+	return new(big.Float).SetPrec(max(floatPrec, uint(n.BitLen()))).SetInt(n)
+}
+
+// parseNumber parses a number literal, the integers are exact at any size
+// and the decimals are rounded to floatPrec bits
+func parseNumber(s string) (*big.Float, error) {
+	if n, ok := new(big.Int).SetString(s, 10); ok {
+		return intToFloat(n), nil
+	}
+	x, _, err := big.ParseFloat(s, 10, floatPrec, big.ToNearestEven)
+	return x, err
+}
+
+// integerOperator computes x + y, x - y, x*y and x^y of integers with
+// big.Int so they are exact, ok is false for other operands and when the
+// result would have more than maxExactBits. The results larger than the
+// integers of a float64 are a *big.Int like the factorials, so every digit
+// is printed
+func integerOperator(name string, x, y *big.Float) (z Value, ok bool) {
+	if x.IsInf() || y.IsInf() || !x.IsInt() || !y.IsInt() {
+		return nil, false
+	}
+	a, _ := x.Int(nil)
+	b, _ := y.Int(nil)
+	var bits int
+	switch name {
+	case "+", "-":
+		bits = max(a.BitLen(), b.BitLen()) + 1
+	case "*":
+		bits = a.BitLen() + b.BitLen()
+	case "^":
+		if b.Sign() < 0 || !b.IsInt64() {
+			return nil, false
+		}
+		bits = powBits(a, b.Int64())
+	default:
+		return nil, false
+	}
+	if bits > maxExactBits {
+		return nil, false
+	}
+	switch name {
+	case "+":
+		a.Add(a, b)
+	case "-":
+		a.Sub(a, b)
+	case "*":
+		a.Mul(a, b)
+	case "^":
+		a.Exp(a, b, nil)
+	}
+	if a.BitLen() > maxFloat64Bits {
+		return a, true
+	}
+	return intToFloat(a), true
+}
+
+// powBits estimates the bits of a^k by k*log2(a), 2^100000 has 100001 bits
+func powBits(a *big.Int, k int64) int {
+	if a.CmpAbs(big.NewInt(1)) <= 0 {
+		return 1
+	}
+	mant := new(big.Float).SetInt(a)
+	exp := mant.MantExp(mant)
+	m, _ := mant.Abs(mant).Float64()
+	bits := math.Ceil(float64(k)*(float64(exp)+math.Log2(m))) + 1
+	return int(min(bits, math.MaxInt32))
+}
+
+// integerArgs converts the arguments to integers, the values rounded near
+// an integer like 0.1*30 are accepted but not the ones whose digits were
+// rounded, like 2^300/3*3
+func integerArgs(args []Value) ([]*big.Int, error) {
+	ints := make([]*big.Int, len(args))
+	for i, arg := range args {
+		x, err := Real(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%s argument not supported", typeName(arg))
+		}
+		if x.IsInf() {
+			return nil, errNotInteger
+		}
+		n := nearestInt(x)
+		if x.IsInt() && x.Acc() == big.Exact {
+			ints[i] = n
+			continue
+		}
+		if n.BitLen()+integerGuardBits > int(x.Prec()) {
+			return nil, errRounded
+		}
+		diff := new(big.Float).Sub(x, new(big.Float).SetInt(n))
+		tolerance := new(big.Float).Abs(x)
+		tolerance.Add(tolerance, big.NewFloat(1)).Mul(tolerance, integerTolerance)
+		if diff.Abs(diff).Cmp(tolerance) > 0 {
+			return nil, errNotInteger
+		}
+		ints[i] = n
+	}
+	return ints, nil
+}
+
+// nearestInt rounds x to the nearest integer
+func nearestInt(x *big.Float) *big.Int {
+	half := big.NewFloat(0.5)
+	if x.Sign() < 0 {
+		half.Neg(half)
+	}
+	n, _ := new(big.Float).SetPrec(x.Prec()+1).Add(x, half).Int(nil)
+	return n
+}
+
+// smallArgs converts the arguments to int64 limited to maxFactorial
+func smallArgs(args []Value) ([]int64, error) {
+	ints, err := integerArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	small := make([]int64, len(ints))
+	for i, n := range ints {
+		if n.Sign() < 0 {
+			return nil, errNegative
+		}
+		if n.Cmp(big.NewInt(maxFactorial)) > 0 {
+			return nil, fmt.Errorf("the arguments must not be greater than %d", maxFactorial)
+		}
+		small[i] = n.Int64()
+	}
+	return small, nil
+}
+
+func factorial(args []Value) (Value, error) {
+	n, err := smallArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).MulRange(1, n[0]), nil
+}
+
+// nCr returns the combinations of n items taken r at a time
+func nCr(args []Value) (Value, error) {
+	ints, err := integerArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	n, r := ints[0], ints[1]
+	if n.Sign() < 0 || r.Sign() < 0 {
+		return nil, errNegative
+	}
+	if r.Cmp(n) > 0 {
+		return new(big.Int), nil
+	}
+	if !n.IsInt64() {
+		return nil, errors.New("n is too large")
+	}
+	// the smaller of r and n - r is the number of factors
+	if min(r.Int64(), n.Int64()-r.Int64()) > maxFactorial {
+		return nil, fmt.Errorf("r and n - r must not both be greater than %d", maxFactorial)
+	}
+	return new(big.Int).Binomial(n.Int64(), r.Int64()), nil
+}
+
+// nPr returns the permutations of n items taken r at a time
+func nPr(args []Value) (Value, error) {
+	ints, err := integerArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	n, r := ints[0], ints[1]
+	if n.Sign() < 0 || r.Sign() < 0 {
+		return nil, errNegative
+	}
+	if r.Cmp(n) > 0 {
+		return new(big.Int), nil
+	}
+	if !n.IsInt64() {
+		return nil, errors.New("n is too large")
+	}
+	if r.Int64() > maxFactorial {
+		return nil, fmt.Errorf("r must not be greater than %d", maxFactorial)
+	}
+	return new(big.Int).MulRange(n.Int64()-r.Int64()+1, n.Int64()), nil
+}
+
+func isPrime(args []Value) (Value, error) {
+	n, err := integerArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	if n[0].BitLen() > maxModularBits {
+		return nil, fmt.Errorf("the argument must not have more than %d bits", maxModularBits)
+	}
+	return &Bool{Value: n[0].ProbablyPrime(20)}, nil
+}
+
+func gcd(args []Value) (Value, error) {
+	ints, err := integerArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	d := new(big.Int).Abs(ints[0])
+	for _, n := range ints[1:] {
+		d.GCD(nil, nil, d, n)
+	}
+	return d, nil
+}
+
+func lcm(args []Value) (Value, error) {
+	ints, err := integerArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	m := new(big.Int).Abs(ints[0])
+	for _, n := range ints[1:] {
+		if m.Sign() == 0 || n.Sign() == 0 {
+			return new(big.Int), nil
+		}
+		d := new(big.Int).GCD(nil, nil, m, n)
+		m.Mul(m, new(big.Int).Quo(n, d))
+		m.Abs(m)
+	}
+	return m, nil
+}
+
+// powMod returns powmod(b, e, m) = b^e mod m, a negative exponent uses the
+// modular inverse of b
+func powMod(args []Value) (Value, error) {
+	ints, err := integerArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	b, e, m := ints[0], ints[1], ints[2]
+	if m.Sign() <= 0 {
+		return nil, errors.New("the modulus must be positive")
+	}
+	if e.BitLen() > maxModularBits || m.BitLen() > maxModularBits {
+		return nil, fmt.Errorf("the exponent and the modulus must not have more than %d bits", maxModularBits)
+	}
+	z := new(big.Int).Exp(b, e, m)
+	if z == nil {
+		return nil, fmt.Errorf("%s has no inverse modulo %s", b, m)
+	}
+	return z.Mod(z, m), nil
+}
+
+// factorFunc returns the prime factorization, the small factors are found by
+// trial division and the large ones by Pollard's rho
+func factorFunc(args []Value, stop func() bool) (Value, error) {
+	ints, err := integerArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	n := ints[0]
+	if n.Cmp(big.NewInt(2)) < 0 {
+		return nil, errors.New("the argument must be greater than 1")
+	}
+	if n.BitLen() > maxModularBits {
+		return nil, fmt.Errorf("the argument must not have more than %d bits", maxModularBits)
+	}
+
+	f := &Factors{N: n}
+	add := func(p *big.Int, power int) {
+		for i, q := range f.Primes {
+			if c := q.Cmp(p); c == 0 {
+				f.Powers[i] += power
+				return
+			} else if c > 0 {
+				f.Primes = append(f.Primes[:i], append([]*big.Int{p}, f.Primes[i:]...)...)
+				f.Powers = append(f.Powers[:i], append([]int{power}, f.Powers[i:]...)...)
+				return
+			}
+		}
+		f.Primes = append(f.Primes, p)
+		f.Powers = append(f.Powers, power)
+	}
+
+	rest := new(big.Int).Set(n)
+	for p := int64(2); p < 1000 && rest.Cmp(big.NewInt(1)) > 0; p++ {
+		if stop() {
+			return nil, ErrDeadline
+		}
+		prime, power := big.NewInt(p), 0
+		for new(big.Int).Mod(rest, prime).Sign() == 0 {
+			rest.Quo(rest, prime)
+			power++
+		}
+		if power > 0 {
+			add(prime, power)
+		}
+	}
+
+	pending := []*big.Int{rest}
+	for len(pending) > 0 {
+		m := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		switch {
+		case m.Cmp(big.NewInt(1)) == 0:
+		case m.ProbablyPrime(20):
+			add(m, 1)
+		default:
+			d := pollardRho(m, stop)
+			if stop() {
+				return nil, ErrDeadline
+			}
+			if d == nil {
+				return nil, fmt.Errorf("could not find a factor of %s", m)
+			}
+			pending = append(pending, d, new(big.Int).Quo(m, d))
+		}
+	}
+	return f, nil
+}
+
+// pollardRho returns a nontrivial factor of the composite n with Floyd's
+// cycle detection, trying other polynomials x^2 + c when a search fails. It
+// returns nil when stop reports true
+func pollardRho(n *big.Int, stop func() bool) *big.Int {
+	one := big.NewInt(1)
+	steps := 0
+	for c := int64(1); steps < maxRhoSteps; c++ {
+		x, y, d := big.NewInt(2), big.NewInt(2), big.NewInt(1)
+		next := func(v *big.Int) {
+			v.Mul(v, v)
+			v.Add(v, big.NewInt(c))
+			v.Mod(v, n)
+		}
+		for d.Cmp(one) == 0 && steps < maxRhoSteps {
+			if stop() {
+				return nil
+			}
+			next(x)
+			next(y)
+			next(y)
+			d.Sub(x, y)
+			d.GCD(nil, nil, d.Abs(d), n)
+			steps++
+		}
+		if d.Cmp(one) != 0 && d.Cmp(n) != 0 {
+			return d
+		}
+	}
+	return nil
+}
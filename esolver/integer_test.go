@@ -0,0 +1,176 @@
+package esolver
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_esolver_Integer(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"factorial(0)", "1"},
+		{"factorial(5)", "120"},
+		{"factorial(30)", "265252859812191058636308480000000"},
+		{"ncr(52, 5)", "2598960"},
+		{"ncr(5, 0)", "1"},
+		{"ncr(3, 5)", "0"},
+		{"ncr(200, 100)", "90548514656103281165404177077484163874504589675413336841320"},
+		{"npr(10, 3)", "720"},
+		{"npr(5, 5)", "120"},
+		{"gcd(48, 180, 30)", "6"},
+		{"gcd(-4, 6)", "2"},
+		{"gcd(0, 7)", "7"},
+		{"lcm(4, 6, 10)", "60"},
+		{"lcm(0, 5)", "0"},
+		{"powmod(2, 100, 1000000007)", "976371285"},
+		{"powmod(-2, 3, 5)", "2"},
+		{"powmod(3, -1, 7)", "5"},
+		{"powmod(2^127 - 1, 2^64, 2^61 - 1)", "1133473550446800462"},
+		{"ncr(10^6, 10^6 - 2)", "499999500000"},
+		{"gcd(123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890, 10)", "10"},
+		{"gcd(2^300 + 6, 3^200*4)", "2"},
+		// 2^100000 has 100001 bits, it is still exact
+		{"gcd(2^100000, 3^50000)", "1"},
+		{"gcd(2^100000 + 2, 2^99999 - 1)", "1"},
+		{"gcd(2^100000, 6)", "2"},
+		// rounded near an integer
+		{"factorial(0.1*30)", "6"},
+		{"ncr(sqrt(2)^2*5, 0.3*10)", "120"},
+		{"gcd(-0.1*30, 9)", "3"},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := e.Eval(tt.expr)
+			if err != nil {
+				t.Fatalf("Eval() failed: %v", err)
+			}
+			n, ok := got.(*big.Int)
+			if !ok {
+				t.Fatalf("Eval() = %T, want *big.Int", got)
+			}
+			if n.String() != tt.want {
+				t.Errorf("Eval() = %v, want %v", n, tt.want)
+			}
+		})
+	}
+}
+
+func Test_esolver_IsPrime(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"isprime(1)", false},
+		{"isprime(2)", true},
+		{"isprime(91)", false},
+		{"isprime(97)", true},
+		{"isprime(2^61 - 1)", true},
+		{"isprime(2^64 + 1)", false},
+		{"isprime(2^521 - 1)", true},
+		{"isprime(2^521 + 1)", false},
+		{"isprime(-7)", false},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := e.Eval(tt.expr)
+			if err != nil {
+				t.Fatalf("Eval() failed: %v", err)
+			}
+			if b, ok := got.(*Bool); !ok || b.Value != tt.want {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_esolver_Factor(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"factor(2)", "2"},
+		{"factor(360)", "2^3 * 3^2 * 5"},
+		{"factor(1001)", "7 * 11 * 13"},
+		{"factor(600851475143)", "71 * 839 * 1471 * 6857"},
+		{"factor(2^64 + 1)", "274177 * 67280421310721"},
+		{"factor(1000003^2)", "1000003^2"},
+		{"factor(factorial(10))", "2^8 * 3^4 * 5^2 * 7"},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := e.Eval(tt.expr)
+			if err != nil {
+				t.Fatalf("Eval() failed: %v", err)
+			}
+			f, ok := got.(*Factors)
+			if !ok {
+				t.Fatalf("Eval() = %T, want *Factors", got)
+			}
+			if f.String() != tt.want {
+				t.Errorf("Eval() = %v, want %v", f, tt.want)
+			}
+		})
+	}
+}
+
+func Test_esolver_IntegerChaining(t *testing.T) {
+	tests := []struct {
+		expr string
+		want *big.Float
+	}{
+		{"factorial(5)/7", new(big.Float).SetPrec(floatPrec).Quo(big.NewFloat(120), big.NewFloat(7))},
+		{"factor(360)/2", big.NewFloat(180)},
+		{"gcd(factorial(6), ncr(10, 3))", big.NewFloat(120)},
+		{"sqrt(ncr(4, 2) + 3)", big.NewFloat(3)},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := e.Solve(tt.expr)
+			if err != nil {
+				t.Fatalf("Solve() failed: %v", err)
+			}
+			if got.Cmp(tt.want) != 0 {
+				t.Errorf("Solve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_esolver_IntegerErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"factorial(2.5)", "factorial: the arguments must be integers"},
+		{"factorial(3 + 10^-30)", "factorial: the arguments must be integers"},
+		{"factorial(-1)", "factorial: the arguments must not be negative"},
+		{"factorial(10001)", "factorial: the arguments must not be greater than 10000"},
+		{"ncr(-1, 2)", "ncr: the arguments must not be negative"},
+		{"npr(20000, 15000)", "npr: r must not be greater than 10000"},
+		{"ncr(10^6, 5*10^5)", "ncr: r and n - r must not both be greater than 10000"},
+		{"gcd(2^300/3*3, 6)", "gcd: the arguments must be exact integers, they were rounded"},
+		{"isprime(2^100000 + 1)", "isprime: the argument must not have more than 8192 bits"},
+		{"powmod(3, 2^100000, 7)", "powmod: the exponent and the modulus must not have more than 8192 bits"},
+		{"factor(1)", "factor: the argument must be greater than 1"},
+		{"factor(2^100000)", "factor: the argument must not have more than 8192 bits"},
+		{"powmod(2, -1, 4)", "powmod: 2 has no inverse modulo 4"},
+		{"powmod(2, 3, 0)", "powmod: the modulus must be positive"},
+		{"gcd(2, 3i)", "gcd: complex argument not supported"},
+		{"lcm(4)", "lcm: wrong number of arguments"},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := e.Eval(tt.expr)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Eval() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package esolver
+
+// Kind is the kind of quantity of a real value, it is found by the
+// evaluation from the number markers and the functions to show angles in
+// degrees, minutes and seconds and durations in hours, minutes and seconds
+type Kind int
+
+const (
+	Plain Kind = iota
+	// Angle is an angle in degrees like 45d30' or the result of atan
+	Angle
+	// Duration is a time in hours like 2h 30m or the difference of dates
+	Duration
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Angle:
+		return "angle"
+	case Duration:
+		return "duration"
+	}
+	return "plain"
+}
+
+// Marker returns the marker printed after the numbers of the kind, parsing
+// 45.5° or 2.5h gives back the kind
+func (k Kind) Marker() string {
+	switch k {
+	case Angle:
+		return "°"
+	case Duration:
+		return "h"
+	}
+	return ""
+}
+
+// keepKind are the functions whose result has the kind of the arguments,
+// like abs(-45d) or mean of angles
+var keepKind = map[string]bool{
+	"abs": true, "ceil": true, "floor": true, "re": true, "conj": true,
+	"sum": true, "mean": true, "median": true, "mode": true, "min": true, "max": true,
+	"stdev": true, "percentile": true,
+}
+
+// angleResult are the functions returning angles in degrees
+var angleResult = map[string]bool{
+	"asin": true, "acos": true, "atan": true, "arg": true, "azimuth": true,
+}
+
+// combineKinds returns the kind shared by the values that are not plain or
+// plain when they have different kinds, so 45d + 1 is an angle
+func combineKinds(kinds ...Kind) Kind {
+	kind := Plain
+	for _, k := range kinds {
+		if k == Plain {
+			continue
+		}
+		if kind != Plain && kind != k {
+			return Plain
+		}
+		kind = k
+	}
+	return kind
+}
+
+// operatorKind returns the kind of the result of an operator, a sum keeps
+// the kind of its terms and a product or a quotient by a plain number keeps
+// the kind of the other operand, 30d/15d is plain
+func operatorKind(name string, x, y Kind) Kind {
+	switch name {
+	case "+", "-":
+		return combineKinds(x, y)
+	case "*":
+		if x == Plain || y == Plain {
+			return combineKinds(x, y)
+		}
+	case "/":
+		if y == Plain {
+			return x
+		}
+	}
+	return Plain
+}
+
+// funcKind returns the kind of the result of a function, in radians the
+// inverse trigonometric functions return plain numbers
+func funcKind(name string, args []Kind, unit AngleUnit) Kind {
+	switch {
+	case angleResult[name]:
+		if unit == Radians && name != "azimuth" {
+			return Plain
+		}
+		return Angle
+	case keepKind[name]:
+		return combineKinds(args...)
+	}
+	return Plain
+}
+
+// valueKind returns the kind of a value by its type, dates are times
+func valueKind(v Value) Kind {
+	if _, ok := v.(*Date); ok {
+		return Duration
+	}
+	return Plain
+}
@@ -0,0 +1,42 @@
+package esolver
+
+import "testing"
+
+func Test_esolver_EvalKind(t *testing.T) {
+	tests := []struct {
+		expr string
+		want Kind
+	}{
+		{"45d30'", Angle},
+		{"45.5", Plain},
+		{"floor(45.5d)", Angle},
+		{"-45d + 1", Angle},
+		{"(45d + 15d)/2", Angle},
+		{"2*45d", Angle},
+		{"30d/15d", Plain},
+		{"sin30d", Plain},
+		{"atan(1)", Angle},
+		{"azimuth(n45e + 180)", Angle},
+		{"mean(10d, 20d)", Angle},
+		{"2h 30m + 45m", Duration},
+		{"2026-12-25 - 2026-10-18", Duration},
+		{"days(2026-12-25 - 2026-10-18)", Plain},
+		{"atan(1) + 2h", Plain},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			stack, err := e.ParseExpression(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseExpression() failed: %v", err)
+			}
+			_, got, err := e.EvalKind(stack)
+			if err != nil {
+				t.Fatalf("EvalKind() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("EvalKind() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package esolver
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var errNotBool = errors.New("operand must be true or false")
+
+// Bool is the result of the comparisons and the logical operators, a
+// comparison keeps the compared values in X and Y
+type Bool struct {
+	Value bool
+	Op    string
+	X     *big.Float
+	Y     *big.Float
+}
+
+func (b *Bool) String() string {
+	if b.Value {
+		return "true"
+	}
+	return "false"
+}
+
+// equalityBits is the number of bits of the less precise operand ignored
+// by the comparisons, so 0.1 + 0.2 == 0.3 and sin30 == 0.5
+const equalityBits = 4
+
+// compare returns the comparison of real numbers, the numbers equal
+// within the precision of the less precise one are equal
+func compare(op string) func(x, y *big.Float) (Value, error) {
+	return func(x, y *big.Float) (Value, error) {
+		c := compareReal(x, y)
+		var result bool
+		switch op {
+		case "<":
+			result = c < 0
+		case "<=":
+			result = c <= 0
+		case ">":
+			result = c > 0
+		case ">=":
+			result = c >= 0
+		case "==":
+			result = c == 0
+		case "!=":
+			result = c != 0
+		}
+		return &Bool{Value: result, Op: op, X: x, Y: y}, nil
+	}
+}
+
+func compareReal(x, y *big.Float) int {
+	if x.IsInf() || y.IsInf() {
+		return x.Cmp(y)
+	}
+	prec := min(x.Prec(), y.Prec())
+	if prec == 0 {
+		prec = floatPrec
+	}
+	tolerance := maxFloat(new(big.Float).Abs(x), new(big.Float).Abs(y))
+	tolerance = new(big.Float).SetMantExp(tolerance, equalityBits-int(prec))
+	diff := new(big.Float).Sub(x, y)
+	if diff.Abs(diff).Cmp(tolerance) <= 0 {
+		return 0
+	}
+	return x.Cmp(y)
+}
+
+var boolConsts = map[string]func() *Bool{
+	"true":  func() *Bool { return &Bool{Value: true} },
+	"false": func() *Bool { return &Bool{Value: false} },
+}
+
+var logicData = map[string]func(x, y Value) (Value, error){
+	"and": logical(func(x, y bool) bool { return x && y }),
+	"or":  logical(func(x, y bool) bool { return x || y }),
+}
+
+func logical(fx func(x, y bool) bool) func(x, y Value) (Value, error) {
+	return func(x, y Value) (Value, error) {
+		xb, xOk := x.(*Bool)
+		yb, yOk := y.(*Bool)
+		if !xOk || !yOk {
+			return nil, errNotBool
+		}
+		return &Bool{Value: fx(xb.Value, yb.Value)}, nil
+	}
+}
+
+func not(x Value) (Value, error) {
+	b, ok := x.(*Bool)
+	if !ok {
+		return nil, fmt.Errorf("not: %w", errNotBool)
+	}
+	return &Bool{Value: !b.Value}, nil
+}
+
+// ifFunc evaluates if(cond, a, b), only the chosen argument is evaluated
+// so if(x > 0, sqrt(x), 0) does not fail for negative x
+func ifFunc(args []Value) (Value, error) {
+	v, err := args[0].(*Expr).Eval(nil)
+	if err != nil {
+		return nil, err
+	}
+	cond, ok := v.(*Bool)
+	if !ok {
+		return nil, errors.New("the condition must be true or false")
+	}
+	if cond.Value {
+		return args[1].(*Expr).Eval(nil)
+	}
+	return args[2].(*Expr).Eval(nil)
+}
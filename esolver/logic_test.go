@@ -0,0 +1,114 @@
+package esolver
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func Test_esolver_Logic(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"1 < 2", true},
+		{"2 < 2", false},
+		{"2 <= 2", true},
+		{"3 > 2", true},
+		{"2 >= 3", false},
+		{"2 ≥ 2", true},
+		{"2 == 2", true},
+		{"2 != 2", false},
+		{"2 ≠ 3", true},
+		// equal within the precision of the operands
+		{"0.1 + 0.2 == 0.3", true},
+		{"sin30 == 0.5", true},
+		{"1 == 1.000001", false},
+		// comparisons bind looser than arithmetic
+		{"1 + 2 == 3", true},
+		{"2*3 > 5", true},
+		{"true and false", false},
+		{"true or false", true},
+		{"not true", false},
+		{"1 < 2 and 2 < 3", true},
+		{"1 > 2 or 2 < 3", true},
+		// and binds tighter than or
+		{"true or true and false", true},
+		{"not 1 > 2", true},
+		{"not 1 < 2 and 1 > 2", false},
+		{"not (true and false)", true},
+		{"if(1 < 2, 1, 0) == 1", true},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			v, err := e.Eval(tt.expr)
+			if err != nil {
+				t.Fatalf("Eval() failed: %v", err)
+			}
+			b, ok := v.(*Bool)
+			if !ok {
+				t.Fatalf("Eval() = %T, want *Bool", v)
+			}
+			if b.Value != tt.want {
+				t.Errorf("Eval() = %v, want %v", b.Value, tt.want)
+			}
+		})
+	}
+}
+
+func Test_esolver_Comparison(t *testing.T) {
+	v, err := New().Eval("150 < 0.6*250")
+	if err != nil {
+		t.Fatalf("Eval() failed: %v", err)
+	}
+	b := v.(*Bool)
+	if b.Value || b.Op != "<" || b.X.Cmp(big.NewFloat(150)) != 0 || b.Y.Cmp(big.NewFloat(150)) != 0 {
+		t.Errorf("Eval() = %v (%v %v %v)", b, b.X, b.Op, b.Y)
+	}
+}
+
+func Test_esolver_If(t *testing.T) {
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"if(2 > 1, 10, 20)", 10},
+		{"if(2 < 1, 10, 20)", 20},
+		// only the chosen argument is evaluated
+		{"if(-4 > 0, sqrt(-4), 0)", 0},
+		{"if(x > 0, x, -x)*2", 6},
+		{"integrate(if(x < 1, x, 1), x, 0, 2)", 1.5},
+	}
+	e := New()
+	e.AddConstant("x", func() *big.Float { return big.NewFloat(-3) })
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := e.Solve(tt.expr)
+			if err != nil {
+				t.Fatalf("Solve() failed: %v", err)
+			}
+			if f, _ := got.Float64(); math.Abs(f-tt.want) > 1e-9 {
+				t.Errorf("Solve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_esolver_LogicErrors(t *testing.T) {
+	e := New()
+	for _, expr := range []string{
+		"1 + (1 < 2)",
+		"1 < 2 < 3",
+		"1 and true",
+		"not 1",
+		"-true",
+		"if(1, 2, 3)",
+		"(1+i) < 2",
+		"1 ! 2",
+	} {
+		if v, err := e.Eval(expr); err == nil {
+			t.Errorf("Eval(%q) = %v, expected error", expr, v)
+		}
+	}
+}
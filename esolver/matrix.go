@@ -0,0 +1,488 @@
+package esolver
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Vector is a list of real numbers, it is treated as a column when
+// multiplied by a matrix on the left and as a row on the right
+type Vector []*big.Float
+
+// Matrix is a list of rows of real numbers with the same length
+type Matrix [][]*big.Float
+
+var (
+	errDimension = errors.New("dimension mismatch")
+	errSingular  = errors.New("singular matrix")
+	errNotSquare = errors.New("matrix is not square")
+	errNotArray  = errors.New("argument must be a vector or matrix")
+	errRealItems = errors.New("vector and matrix elements must be real")
+)
+
+// pivotEpsilon is the relative size under which a pivot is considered zero
+var pivotEpsilon = new(big.Float).SetMantExp(big.NewFloat(1), -200)
+
+func newMatrix(rows, cols int) Matrix {
+	m := make(Matrix, rows)
+	for i := range m {
+		m[i] = make([]*big.Float, cols)
+		for j := range m[i] {
+			m[i][j] = new(big.Float).SetPrec(floatPrec)
+		}
+	}
+	return m
+}
+
+func identity(n int) Matrix {
+	m := newMatrix(n, n)
+	for i := range m {
+		m[i][i].SetInt64(1)
+	}
+	return m
+}
+
+func (m Matrix) clone() Matrix {
+	c := make(Matrix, len(m))
+	for i := range m {
+		c[i] = make([]*big.Float, len(m[i]))
+		for j := range m[i] {
+			c[i][j] = new(big.Float).Set(m[i][j])
+		}
+	}
+	return c
+}
+
+func (m Matrix) String() string {
+	return fmt.Sprint([][]*big.Float(m))
+}
+
+func (v Vector) String() string {
+	return fmt.Sprint([]*big.Float(v))
+}
+
+// newArray creates a vector from a list of numbers or a matrix from a list
+// of vectors, as written in a bracket literal
+func newArray(items []Value) (Value, error) {
+	if len(items) == 0 {
+		return nil, errors.New("empty vector")
+	}
+	switch items[0].(type) {
+	case *big.Float:
+		v := make(Vector, len(items))
+		for i, item := range items {
+			x, ok := item.(*big.Float)
+			if !ok {
+				return nil, errRealItems
+			}
+			v[i] = x
+		}
+		return v, nil
+	case Vector:
+		m := make(Matrix, len(items))
+		for i, item := range items {
+			row, ok := item.(Vector)
+			if !ok || len(row) != len(items[0].(Vector)) {
+				return nil, errors.New("matrix rows must be vectors with the same length")
+			}
+			m[i] = row
+		}
+		return m, nil
+	}
+	return nil, errRealItems
+}
+
+func isArray(v Value) bool {
+	switch v.(type) {
+	case Vector, Matrix:
+		return true
+	}
+	return false
+}
+
+// asMatrix returns the array as a matrix, vectors are a single row
+func asMatrix(v Value) (Matrix, bool) {
+	switch a := v.(type) {
+	case Vector:
+		return Matrix{a}, true
+	case Matrix:
+		return a, true
+	}
+	return nil, false
+}
+
+func column(v Vector) Matrix {
+	m := make(Matrix, len(v))
+	for i, x := range v {
+		m[i] = []*big.Float{x}
+	}
+	return m
+}
+
+// mapElements applies fx to every element of an array
+func mapElements(v Value, fx func(x *big.Float) (Value, error)) (Value, error) {
+	m, _ := asMatrix(v)
+	out := make(Matrix, len(m))
+	for i := range m {
+		out[i] = make([]*big.Float, len(m[i]))
+		for j := range m[i] {
+			r, err := fx(m[i][j])
+			if err != nil {
+				return nil, err
+			}
+			x, ok := r.(*big.Float)
+			if !ok {
+				return nil, errRealItems
+			}
+			out[i][j] = x
+		}
+	}
+	if _, ok := v.(Vector); ok {
+		return Vector(out[0]), nil
+	}
+	return out, nil
+}
+
+// elementwise applies fx to the elements in the same position of both
+// arrays, a real number is combined with every element of the array
+func elementwise(x, y Value, fx func(x, y *big.Float) (Value, error)) (Value, error) {
+	xm, xArr := asMatrix(x)
+	ym, yArr := asMatrix(y)
+	xs, xReal := x.(*big.Float)
+	ys, yReal := y.(*big.Float)
+	if (!xArr && !xReal) || (!yArr && !yReal) {
+		return nil, errRealItems
+	}
+
+	_, xVec := x.(Vector)
+	_, yVec := y.(Vector)
+	if xArr && yArr && (xVec != yVec || len(xm) != len(ym) || len(xm[0]) != len(ym[0])) {
+		return nil, errDimension
+	}
+
+	shape := xm
+	if !xArr {
+		shape = ym
+	}
+	out := make(Matrix, len(shape))
+	for i := range shape {
+		out[i] = make([]*big.Float, len(shape[i]))
+		for j := range shape[i] {
+			a, b := xs, ys
+			if xArr {
+				a = xm[i][j]
+			}
+			if yArr {
+				b = ym[i][j]
+			}
+			r, err := fx(a, b)
+			if err != nil {
+				return nil, err
+			}
+			z, ok := r.(*big.Float)
+			if !ok {
+				return nil, errRealItems
+			}
+			out[i][j] = z
+		}
+	}
+	if xVec || yVec {
+		return Vector(out[0]), nil
+	}
+	return out, nil
+}
+
+func matMul(a, b Matrix) (Matrix, error) {
+	if len(a[0]) != len(b) {
+		return nil, errDimension
+	}
+	out := newMatrix(len(a), len(b[0]))
+	for i := range a {
+		for j := range b[0] {
+			for k := range b {
+				out[i][j].Add(out[i][j], new(big.Float).Mul(a[i][k], b[k][j]))
+			}
+		}
+	}
+	return out, nil
+}
+
+func matPow(m Matrix, y *big.Float) (Matrix, error) {
+	if len(m) != len(m[0]) {
+		return nil, errNotSquare
+	}
+	n, acc := y.Int64()
+	if acc != big.Exact {
+		return nil, errors.New("matrix power must be an integer")
+	}
+	if n < 0 {
+		inv, err := matInv(m)
+		if err != nil {
+			return nil, err
+		}
+		m, n = inv, -n
+	}
+	result := identity(len(m))
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result, _ = matMul(result, m)
+		}
+		m, _ = matMul(m, m)
+	}
+	return result, nil
+}
+
+// arrayOperator evaluates an operator with at least one vector or matrix
+// operand, + - and the dot operators work element by element
+func arrayOperator(name string, x, y Value) (Value, error) {
+	opr := oprData[name]
+	xm, xMat := x.(Matrix)
+	ym, yMat := y.(Matrix)
+	xv, xVec := x.(Vector)
+	yv, yVec := y.(Vector)
+
+	switch name {
+	case "*":
+		switch {
+		case xMat && yMat:
+			return matMul(xm, ym)
+		case xMat && yVec:
+			m, err := matMul(xm, column(yv))
+			if err != nil {
+				return nil, err
+			}
+			return Vector(transpose(m)[0]), nil
+		case xVec && yMat:
+			m, err := matMul(Matrix{xv}, ym)
+			if err != nil {
+				return nil, err
+			}
+			return Vector(m[0]), nil
+		case xVec && yVec:
+			return nil, errors.New("use dot or .* to multiply vectors")
+		}
+	case "/":
+		if isArray(y) {
+			return nil, errors.New("division by a matrix, use inv or solve")
+		}
+	case "^":
+		if yf, ok := y.(*big.Float); ok && xMat {
+			return matPow(xm, yf)
+		}
+		return nil, errors.New("power of a matrix must be a square matrix and an integer")
+	case "∠":
+		return nil, errRealItems
+	}
+	return elementwise(x, y, opr.fx)
+}
+
+func transpose(m Matrix) Matrix {
+	out := make(Matrix, len(m[0]))
+	for j := range out {
+		out[j] = make([]*big.Float, len(m))
+		for i := range m {
+			out[j][i] = m[i][j]
+		}
+	}
+	return out
+}
+
+// eliminate reduces the square matrix a with gaussian elimination with
+// partial pivoting applying the same row operations to b, it returns the
+// determinant of a
+func eliminate(a, b Matrix) (*big.Float, error) {
+	n := len(a)
+	det := new(big.Float).SetPrec(floatPrec).SetInt64(1)
+
+	tolerance := new(big.Float)
+	for i := range a {
+		for j := range a[i] {
+			if x := new(big.Float).Abs(a[i][j]); x.Cmp(tolerance) > 0 {
+				tolerance = x
+			}
+		}
+	}
+	tolerance.Mul(tolerance, pivotEpsilon)
+
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if new(big.Float).Abs(a[row][col]).Cmp(new(big.Float).Abs(a[pivot][col])) > 0 {
+				pivot = row
+			}
+		}
+		if new(big.Float).Abs(a[pivot][col]).Cmp(tolerance) <= 0 {
+			return new(big.Float), errSingular
+		}
+		if pivot != col {
+			a[pivot], a[col] = a[col], a[pivot]
+			if b != nil {
+				b[pivot], b[col] = b[col], b[pivot]
+			}
+			det.Neg(det)
+		}
+		det.Mul(det, a[col][col])
+
+		for row := 0; row < n; row++ {
+			if row == col || (b == nil && row < col) {
+				continue
+			}
+			factor := new(big.Float).Quo(a[row][col], a[col][col])
+			for j := col; j < n; j++ {
+				a[row][j].Sub(a[row][j], new(big.Float).Mul(factor, a[col][j]))
+			}
+			if b != nil {
+				for j := range b[row] {
+					b[row][j].Sub(b[row][j], new(big.Float).Mul(factor, b[col][j]))
+				}
+			}
+		}
+	}
+
+	if b != nil {
+		for row := range b {
+			for j := range b[row] {
+				b[row][j].Quo(b[row][j], a[row][row])
+			}
+		}
+	}
+	return det, nil
+}
+
+func square(v Value) (Matrix, error) {
+	m, ok := v.(Matrix)
+	if !ok {
+		return nil, errors.New("argument must be a matrix")
+	}
+	if len(m) != len(m[0]) {
+		return nil, errNotSquare
+	}
+	return m, nil
+}
+
+func matInv(m Matrix) (Matrix, error) {
+	inv := identity(len(m))
+	if _, err := eliminate(m.clone(), inv); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func det(args []Value) (Value, error) {
+	m, err := square(args[0])
+	if err != nil {
+		return nil, err
+	}
+	d, err := eliminate(m.clone(), nil)
+	if errors.Is(err, errSingular) {
+		return new(big.Float), nil
+	}
+	return d, err
+}
+
+func inv(args []Value) (Value, error) {
+	m, err := square(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return matInv(m)
+}
+
+func transposeFunc(args []Value) (Value, error) {
+	switch a := args[0].(type) {
+	case Vector:
+		return a, nil
+	case Matrix:
+		return transpose(a), nil
+	}
+	return nil, errNotArray
+}
+
+// solveLinear solves the system A x = b
+func solveLinear(args []Value) (Value, error) {
+	a, err := square(args[0])
+	if err != nil {
+		return nil, err
+	}
+	var b Matrix
+	switch v := args[1].(type) {
+	case Vector:
+		b = column(v)
+	case Matrix:
+		b = v
+	default:
+		return nil, errNotArray
+	}
+	if len(b) != len(a) {
+		return nil, errDimension
+	}
+
+	x := b.clone()
+	if _, err := eliminate(a.clone(), x); err != nil {
+		return nil, err
+	}
+	if _, ok := args[1].(Vector); ok {
+		return Vector(transpose(x)[0]), nil
+	}
+	return x, nil
+}
+
+func vectors(args []Value) (Vector, Vector, error) {
+	a, aOk := args[0].(Vector)
+	b, bOk := args[1].(Vector)
+	if !aOk || !bOk {
+		return nil, nil, errors.New("arguments must be vectors")
+	}
+	if len(a) != len(b) {
+		return nil, nil, errDimension
+	}
+	return a, b, nil
+}
+
+func dot(args []Value) (Value, error) {
+	a, b, err := vectors(args)
+	if err != nil {
+		return nil, err
+	}
+	sum := new(big.Float)
+	for i := range a {
+		sum.Add(sum, new(big.Float).Mul(a[i], b[i]))
+	}
+	return sum, nil
+}
+
+func cross(args []Value) (Value, error) {
+	a, b, err := vectors(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(a) != 3 {
+		return nil, errors.New("cross product needs vectors with 3 elements")
+	}
+	term := func(i, j int) *big.Float {
+		x := new(big.Float).Mul(a[i], b[j])
+		return x.Sub(x, new(big.Float).Mul(a[j], b[i]))
+	}
+	return Vector{term(1, 2), term(2, 0), term(0, 1)}, nil
+}
+
+func norm(args []Value) (Value, error) {
+	switch x := args[0].(type) {
+	case *big.Float:
+		return new(big.Float).Abs(x), nil
+	case *Complex:
+		return x.Abs(), nil
+	}
+	m, ok := asMatrix(args[0])
+	if !ok {
+		return nil, errNotArray
+	}
+	sum := new(big.Float)
+	for i := range m {
+		for j := range m[i] {
+			sum.Add(sum, new(big.Float).Mul(m[i][j], m[i][j]))
+		}
+	}
+	return sum.Sqrt(sum), nil
+}
@@ -0,0 +1,143 @@
+package esolver
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_esolver_EvalArray(t *testing.T) {
+	tests := []struct {
+		expr string
+		want Value
+	}{
+		{"[1, 2, 3]", vector(1, 2, 3)},
+		{"[[1,2],[3,4]]", matrix(2, 1, 2, 3, 4)},
+		{"[1,2,3]+[4,5,6]", vector(5, 7, 9)},
+		{"[1,2,3]-1", vector(0, 1, 2)},
+		{"2[1,2,3]", vector(2, 4, 6)},
+		{"-[1,2]", vector(-1, -2)},
+		{"[1,2].*[3,4]", vector(3, 8)},
+		{"[2,4]./[2,8]", vector(1, 0.5)},
+		{"[1,2,3].^2", vector(1, 4, 9)},
+		{"sqrt([1,4,9])", vector(1, 2, 3)},
+		{"[[1,2],[3,4]]*[[5,6],[7,8]]", matrix(2, 19, 22, 43, 50)},
+		{"[[1,2],[3,4]]*[1,1]", vector(3, 7)},
+		{"[1,1]*[[1,2],[3,4]]", vector(4, 6)},
+		{"[[1,1],[0,1]]^3", matrix(2, 1, 3, 0, 1)},
+		{"[[1,2],[3,4]]^-1", matrix(2, -2, 1, 1.5, -0.5)},
+		{"dot([1,2,3],[4,5,6])", big.NewFloat(32)},
+		{"cross([1,0,0],[0,1,0])", vector(0, 0, 1)},
+		{"norm([3,4])", big.NewFloat(5)},
+		{"norm([[1,1],[1,1]])", big.NewFloat(2)},
+		{"det([[1,2],[3,4]])", big.NewFloat(-2)},
+		{"det([[0,1],[1,0]])", big.NewFloat(-1)},
+		{"det([[2,0,0],[0,3,0],[0,0,4]])", big.NewFloat(24)},
+		{"det([[1,2],[2,4]])", big.NewFloat(0)},
+		{"inv([[1,2],[3,4]])", matrix(2, -2, 1, 1.5, -0.5)},
+		{"transpose([[1,2,3],[4,5,6]])", matrix(2, 1, 4, 2, 5, 3, 6)},
+		{"solve([[2,1],[1,3]],[3,5])", vector(0.8, 1.4)},
+		{"solve([[0,1],[1,0]],[[1,2],[3,4]])", matrix(2, 3, 4, 1, 2)},
+		{"solve([[4,-2,1],[3,6,-4],[2,1,8]],[12,-25,32])", vector(1, -2, 4)},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := e.Eval(tt.expr)
+			if err != nil {
+				t.Fatalf("Eval() failed: %v", err)
+			}
+			if !sameValue(got, tt.want) {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_esolver_EvalArrayErrors(t *testing.T) {
+	e := New()
+	for _, expr := range []string{
+		"[]",
+		"[1,2]+[1,2,3]",
+		"[[1,2],[3]]",
+		"[1,[2]]",
+		"[1,2]*[3,4]",
+		"2/[1,2]",
+		"[1,2]^2",
+		"[1,i]",
+		"inv([[1,2],[2,4]])",
+		"inv([[1,2,3],[4,5,6]])",
+		"cross([1,2],[3,4])",
+		"dot([1,2],[1])",
+		"dot([1,2])",
+		"solve([[1,0],[0,1]],[1,2,3])",
+	} {
+		if v, err := e.Eval(expr); err == nil {
+			t.Errorf("Eval(%q) = %v, expected error", expr, v)
+		}
+	}
+}
+
+func TestParseSeparator(t *testing.T) {
+	e := New()
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"1,5+1", 2.5},
+		{"sqrt(2,25)", 1.5},
+		{"dot([1,5],[2,1])", 7},
+		{"dot([1;5];[2;1])", 7},
+	}
+	for _, tt := range tests {
+		x, err := e.Solve(tt.expr)
+		if err != nil || x.Cmp(big.NewFloat(tt.want)) != 0 {
+			t.Errorf("Solve(%q) = %v %v, want %v", tt.expr, x, err, tt.want)
+		}
+	}
+}
+
+func vector(items ...float64) Vector {
+	v := make(Vector, len(items))
+	for i, x := range items {
+		v[i] = big.NewFloat(x)
+	}
+	return v
+}
+
+func matrix(cols int, items ...float64) Matrix {
+	var m Matrix
+	for i := 0; i < len(items); i += cols {
+		m = append(m, vector(items[i:i+cols]...))
+	}
+	return m
+}
+
+func sameValue(x, y Value) bool {
+	xm, xArr := asMatrix(x)
+	ym, yArr := asMatrix(y)
+	if !xArr || !yArr {
+		xf, xOk := x.(*big.Float)
+		yf, yOk := y.(*big.Float)
+		if !xOk || !yOk {
+			return false
+		}
+		xm, ym = Matrix{{xf}}, Matrix{{yf}}
+	}
+	_, xVec := x.(Vector)
+	_, yVec := y.(Vector)
+	if xVec != yVec || len(xm) != len(ym) {
+		return false
+	}
+	for i := range xm {
+		if len(xm[i]) != len(ym[i]) {
+			return false
+		}
+		for j := range xm[i] {
+			diff := new(big.Float).Sub(xm[i][j], ym[i][j])
+			if diff.Abs(diff).Cmp(big.NewFloat(0.0000000001)) > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
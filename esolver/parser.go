@@ -11,10 +11,11 @@ type Parser struct {
 		tok Token
 		n   int
 	}
+	valueFuncs map[string]ValueFunction
 }
 
 func NewParser(r io.Reader, elemNames map[string]TokenType) *Parser {
-	return &Parser{s: NewScanner(r, elemNames)}
+	return &Parser{s: NewScanner(r, elemNames), valueFuncs: valueFuncs}
 }
 
 func (p *Parser) Scan() (tok Token) {
@@ -42,17 +43,34 @@ func (p *Parser) Unscan() {
 	p.buf.n = 1
 }
 
+// Parse reads all tokens. A comma separates the items of brackets and the
+// arguments of functions with more than one argument, anywhere else it is
+// a decimal separator
 func (p *Parser) Parse() (Stack, error) {
 	stack := Stack{}
+	var separates []bool // for each open parenthesis or bracket
 	for {
+		p.s.DecimalComma = len(separates) == 0 || !separates[len(separates)-1]
 		tok := p.ScanIgnoreWhitespace()
 		if tok.Type == ERROR {
 			return Stack{}, fmt.Errorf("ERROR: %q", tok.Value)
 		} else if tok.Type == EOF {
 			break
-		} else {
-			stack.Push(tok)
 		}
+
+		switch tok.Type {
+		case LPAREN:
+			last := stack.Peek()
+			_, multiArg := p.valueFuncs[last.Value]
+			separates = append(separates, !stack.IsEmpty() && last.Type == FUNCTION && multiArg)
+		case LBRACKET:
+			separates = append(separates, true)
+		case RPAREN, RBRACKET:
+			if len(separates) > 0 {
+				separates = separates[:len(separates)-1]
+			}
+		}
+		stack.Push(tok)
 	}
 	return stack, nil
 }
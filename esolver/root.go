@@ -0,0 +1,289 @@
+package esolver
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Root is the result of solve, in any other operation it is the real root
+type Root struct {
+	X          *big.Float
+	Residual   *big.Float
+	Iterations int
+}
+
+func (r *Root) String() string {
+	return r.X.String()
+}
+
+const (
+	maxSecantIterations = 60
+	maxBrentIterations  = 1000
+	maxBracketSteps     = 60
+)
+
+var (
+	errNoConvergence = errors.New("no convergence")
+	errNoSignChange  = errors.New("no convergence and no sign change found near the guess")
+
+	// rootTolerance is the relative tolerance of the root
+	rootTolerance = new(big.Float).SetMantExp(big.NewFloat(1), 16-floatPrec)
+	// residualTolerance is the tolerance of the value at the root relative
+	// to the value at the guess
+	residualTolerance = new(big.Float).SetMantExp(big.NewFloat(1), -floatPrec/2)
+)
+
+// solve finds a root of the equation solve(x^3 - 2x = 5, x, 2) starting at
+// the guess, 1 by default. The variable may be omitted when the equation has
+// a single unknown. With a matrix and a vector solve(A, b) solves the linear
+// system A x = b
+func solve(args []Value) (Value, error) {
+	exprs := make([]*Expr, len(args))
+	for i, arg := range args {
+		exprs[i] = arg.(*Expr)
+	}
+
+	if len(exprs) == 2 {
+		if a, err := exprs[0].Eval(nil); err == nil && isArray(a) {
+			b, err := exprs[1].Eval(nil)
+			if err != nil {
+				return nil, err
+			}
+			return solveLinear([]Value{a, operand(b)})
+		}
+	}
+
+	equation := exprs[0].equation()
+	name, err := variableName(equation, args[1:min(len(args), 2)])
+	if err != nil {
+		return nil, err
+	}
+
+	guess := big.NewFloat(1)
+	if len(exprs) > 2 {
+		v, err := exprs[2].Eval(nil)
+		if err != nil {
+			return nil, err
+		}
+		if guess, err = Real(operand(v)); err != nil {
+			return nil, fmt.Errorf("guess: %w", err)
+		}
+	}
+
+	f := func(x *big.Float) (*big.Float, error) {
+		v, err := equation.Eval(map[string]Value{name: x})
+		if err != nil {
+			return nil, err
+		}
+		return Real(operand(v))
+	}
+	return findRoot(f, new(big.Float).SetPrec(floatPrec).Set(guess))
+}
+
+type rootFinder struct {
+	f          func(x *big.Float) (*big.Float, error)
+	iterations int
+}
+
+// findRoot uses the secant method from the guess, when it does not converge
+// the root is bracketed and refined with the Brent method. The steps also
+// converge to a pole or a jump like the one of 1/x = 0 at 0, so the value at
+// the root must be near zero
+func findRoot(f func(x *big.Float) (*big.Float, error), guess *big.Float) (*Root, error) {
+	root, err := searchRoot(f, guess)
+	if err != nil {
+		return nil, err
+	}
+	fGuess, err := f(guess)
+	if err != nil {
+		return nil, err
+	}
+	scale := new(big.Float).Abs(fGuess)
+	if scale.Cmp(big.NewFloat(1)) < 0 || scale.IsInf() {
+		scale.SetInt64(1)
+	}
+	if root.Residual.IsInf() || new(big.Float).Abs(root.Residual).Cmp(scale.Mul(scale, residualTolerance)) > 0 {
+		return nil, errNoConvergence
+	}
+	return root, nil
+}
+
+// searchRoot returns the point where the steps of findRoot converge
+func searchRoot(f func(x *big.Float) (*big.Float, error), guess *big.Float) (*Root, error) {
+	r := &rootFinder{f: f}
+	x0 := guess
+	f0, err := f(x0)
+	if err != nil {
+		return nil, err
+	}
+	if f0.Sign() == 0 {
+		return &Root{X: x0, Residual: f0}, nil
+	}
+
+	h := new(big.Float).Abs(x0)
+	if h.Sign() == 0 {
+		h.SetInt64(1)
+	}
+	h.Mul(h, big.NewFloat(1e-4))
+	x1 := new(big.Float).Add(x0, h)
+	f1, err := f(x1)
+	if err != nil {
+		return nil, err
+	}
+
+	var a, fa, b, fb *big.Float // bracket with a sign change
+	for r.iterations = 1; r.iterations <= maxSecantIterations; r.iterations++ {
+		if f1.Sign() == 0 {
+			return &Root{X: x1, Residual: f1, Iterations: r.iterations}, nil
+		}
+		if f0.Sign() != f1.Sign() {
+			a, fa, b, fb = x0, f0, x1, f1
+		}
+
+		df := new(big.Float).Sub(f1, f0)
+		if df.Sign() == 0 {
+			break
+		}
+		step := new(big.Float).Sub(x1, x0)
+		step.Mul(step, f1).Quo(step, df)
+		x2 := new(big.Float).Sub(x1, step)
+		if x2.IsInf() {
+			break
+		}
+		f2, err := f(x2)
+		if err != nil {
+			break
+		}
+		if isConverged(step, x2) {
+			return &Root{X: x2, Residual: f2, Iterations: r.iterations}, nil
+		}
+		x0, f0, x1, f1 = x1, f1, x2, f2
+	}
+
+	if a == nil {
+		var ok bool
+		if a, fa, b, fb, ok = r.bracket(guess); !ok {
+			return nil, errNoSignChange
+		}
+	}
+	return r.brent(a, fa, b, fb)
+}
+
+func isConverged(step, x *big.Float) bool {
+	tolerance := new(big.Float).Abs(x)
+	tolerance.Add(tolerance, big.NewFloat(1)).Mul(tolerance, rootTolerance)
+	return new(big.Float).Abs(step).Cmp(tolerance) <= 0
+}
+
+// bracket looks for a sign change in intervals growing around the guess
+func (r *rootFinder) bracket(guess *big.Float) (a, fa, b, fb *big.Float, ok bool) {
+	fGuess, err := r.f(guess)
+	if err != nil {
+		return nil, nil, nil, nil, false
+	}
+	h := new(big.Float).Abs(guess)
+	if h.Sign() == 0 {
+		h.SetInt64(1)
+	}
+	h.Mul(h, big.NewFloat(0.01))
+
+	for i := 0; i < maxBracketSteps; i++ {
+		for _, x := range []*big.Float{new(big.Float).Add(guess, h), new(big.Float).Sub(guess, h)} {
+			fx, err := r.f(x)
+			if err != nil {
+				continue
+			}
+			if fx.Sign() != fGuess.Sign() {
+				return guess, fGuess, x, fx, true
+			}
+		}
+		h.Mul(h, big.NewFloat(2))
+	}
+	return nil, nil, nil, nil, false
+}
+
+// brent finds the root inside the bracket [a, b] with the method of Brent
+// combining inverse quadratic interpolation, secant and bisection
+func (r *rootFinder) brent(a, fa, b, fb *big.Float) (*Root, error) {
+	sub := func(x, y *big.Float) *big.Float { return new(big.Float).Sub(x, y) }
+	mul := func(x, y *big.Float) *big.Float { return new(big.Float).Mul(x, y) }
+	quo := func(x, y *big.Float) *big.Float { return new(big.Float).Quo(x, y) }
+	abs := func(x *big.Float) *big.Float { return new(big.Float).Abs(x) }
+	one := big.NewFloat(1)
+	half := big.NewFloat(0.5)
+
+	c, fc := b, fb
+	var d, e *big.Float
+	for i := 0; i < maxBrentIterations; i++ {
+		r.iterations++
+		if fb.Sign() == fc.Sign() {
+			c, fc = a, fa
+			d = sub(b, a)
+			e = d
+		}
+		if abs(fc).Cmp(abs(fb)) < 0 {
+			a, b, c = b, c, b
+			fa, fb, fc = fb, fc, fb
+		}
+
+		tol := abs(b)
+		tol.Add(tol, one).Mul(tol, rootTolerance)
+		xm := mul(sub(c, b), half)
+		if abs(xm).Cmp(tol) <= 0 || fb.Sign() == 0 {
+			return &Root{X: b, Residual: fb, Iterations: r.iterations}, nil
+		}
+
+		if abs(e).Cmp(tol) >= 0 && abs(fa).Cmp(abs(fb)) > 0 {
+			var p, q *big.Float
+			s := quo(fb, fa)
+			if a.Cmp(c) == 0 {
+				// secant
+				p = mul(mul(big.NewFloat(2), xm), s)
+				q = sub(one, s)
+			} else {
+				// inverse quadratic interpolation
+				q = quo(fa, fc)
+				t := quo(fb, fc)
+				p = mul(mul(mul(big.NewFloat(2), xm), q), sub(q, t))
+				p = mul(s, sub(p, mul(sub(b, a), sub(t, one))))
+				q = mul(mul(sub(q, one), sub(t, one)), sub(s, one))
+			}
+			if p.Sign() > 0 {
+				q.Neg(q)
+			}
+			p.Abs(p)
+			min1 := sub(mul(mul(big.NewFloat(3), xm), q), abs(mul(tol, q)))
+			min2 := abs(mul(e, q))
+			if mul(big.NewFloat(2), p).Cmp(minFloat(min1, min2)) < 0 {
+				e = d
+				d = quo(p, q)
+			} else {
+				d, e = xm, xm
+			}
+		} else {
+			d, e = xm, xm
+		}
+
+		a, fa = b, fb
+		if abs(d).Cmp(tol) > 0 {
+			b = new(big.Float).Add(b, d)
+		} else if xm.Sign() > 0 {
+			b = new(big.Float).Add(b, tol)
+		} else {
+			b = new(big.Float).Sub(b, tol)
+		}
+		var err error
+		if fb, err = r.f(b); err != nil {
+			return nil, err
+		}
+	}
+	return nil, errNoConvergence
+}
+
+func minFloat(x, y *big.Float) *big.Float {
+	if x.Cmp(y) < 0 {
+		return x
+	}
+	return y
+}
@@ -0,0 +1,71 @@
+package esolver
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_esolver_SolveRoot(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"solve(x^3 - 2x - 5 = 0, x, 2)", "2.09455148154232659148238654057930296385730610562823918030412852904531218998"},
+		{"solve(x^2 = 2)", "1.41421356237309504880168872420969807856967187537694807317667973799073247846"},
+		{"solve(t^2 - 4, t, -5)", "-2"},
+		{"solve(x^2 = 4x - 3, x, 10)", "3"},
+		{"solve(sqrt(x) = 3, x, 1)", "9"},
+		{"2*solve(x^2 = 2)", "2.82842712474619009760337744841939615713934375075389614635335947598146495692"},
+		// the variable shadows the constant e
+		{"solve(e^2 = 9, e, 1)", "3"},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			want, _, _ := big.ParseFloat(tt.want, 10, floatPrec, big.ToNearestEven)
+			x, err := e.Solve(tt.expr)
+			if err != nil {
+				t.Fatalf("Solve() failed: %v", err)
+			}
+			diff := new(big.Float).Sub(x, want)
+			if diff.Abs(diff).Cmp(big.NewFloat(1e-70)) > 0 {
+				t.Errorf("Solve() = %v, want %v", x.Text('g', 75), tt.want)
+			}
+		})
+	}
+}
+
+func Test_esolver_SolveRootResult(t *testing.T) {
+	v, err := New().Eval("solve(x^3 - 2x - 5 = 0, x, 2)")
+	if err != nil {
+		t.Fatalf("Eval() failed: %v", err)
+	}
+	r, ok := v.(*Root)
+	if !ok {
+		t.Fatalf("Eval() = %T, want *Root", v)
+	}
+	if r.Iterations == 0 || r.Iterations > maxSecantIterations {
+		t.Errorf("Iterations = %v", r.Iterations)
+	}
+	if new(big.Float).Abs(r.Residual).Cmp(big.NewFloat(1e-70)) > 0 {
+		t.Errorf("Residual = %v", r.Residual)
+	}
+}
+
+func Test_esolver_SolveRootErrors(t *testing.T) {
+	e := New()
+	for _, expr := range []string{
+		"solve(x^2 + 1 = 0, x, 1)",
+		"solve(x^2 - y = 0)",
+		"solve(x^2 = 2, 3)",
+		"x^2 = 2",
+		"1 = 1",
+		// the steps converge to the pole and the jump, not to roots
+		"solve(1/x = 0, x, 1)",
+		"solve(x/abs(x) + 0.5, x, 1)",
+	} {
+		if v, err := e.Eval(expr); err == nil {
+			t.Errorf("Eval(%q) = %v, expected error", expr, v)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"io"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 )
@@ -11,12 +12,21 @@ import (
 type Scanner struct {
 	r         *bufio.Reader
 	elemNames map[string]TokenType
+	// DecimalComma scans a comma as decimal separator instead of SEPARATOR
+	DecimalComma bool
+	// TimeMode scans the number markers as times: 1d 2h 30m 15s in hours,
+	// otherwise a number with the h marker is a time and the operands of
+	// the others decide it when they are evaluated
+	TimeMode bool
+	// offset counts the runes read
+	offset int
 }
 
 func NewScanner(r io.Reader, elemNames map[string]TokenType) *Scanner {
 	return &Scanner{
-		r:         bufio.NewReader(r),
-		elemNames: elemNames,
+		r:            bufio.NewReader(r),
+		elemNames:    elemNames,
+		DecimalComma: true,
 	}
 }
 
@@ -25,11 +35,19 @@ func (s *Scanner) Read() rune {
 	if err != nil {
 		return eof
 	}
+	s.offset++
 	return ch
 }
 
 func (s *Scanner) Unread() {
-	_ = s.r.UnreadRune()
+	if s.r.UnreadRune() == nil {
+		s.offset--
+	}
+}
+
+// Offset returns the number of runes read, the position of the next token
+func (s *Scanner) Offset() int {
+	return s.offset
 }
 
 func (s *Scanner) Peek(nRunes int) []rune {
@@ -37,12 +55,13 @@ func (s *Scanner) Peek(nRunes int) []rune {
 		return nil
 	}
 	var runes []rune
+	offset := 0
 	for i := 0; i < nRunes; i++ {
-		b, err := s.r.Peek(i + 1)
+		b, err := s.r.Peek(offset + 1)
 		if err != nil {
 			return runes
 		}
-		firstByte := b[i]
+		firstByte := b[offset]
 
 		var size int
 		// Determina o tamanho do rune a partir do primeiro byte.
@@ -57,26 +76,51 @@ func (s *Scanner) Peek(nRunes int) []rune {
 		} else {
 			return runes
 		}
-		b, err = s.r.Peek(i + size)
+		b, err = s.r.Peek(offset + size)
 		if err != nil {
 			return runes
 		}
-		r, _ := utf8.DecodeRune(b[i:])
+		r, _ := utf8.DecodeRune(b[offset:])
 		runes = append(runes, r)
+		offset += size
 	}
 	return runes
 }
 
 func (s *Scanner) Scan() Token {
+	// element by element operators .* ./ .^
+	if next := s.Peek(2); len(next) == 2 && next[0] == '.' && (next[1] == '*' || next[1] == '/' || next[1] == '^') {
+		s.Read()
+		s.Read()
+		return Token{Type: OPERATOR, Value: string(next)}
+	}
+
 	ch := s.Read()
-	if isNumber(ch) {
+	if op, ok := comparisons[ch]; ok {
+		if next := s.Peek(1); len(next) == 1 && next[0] == '=' {
+			s.Read()
+			return Token{Type: OPERATOR, Value: op + "="}
+		}
+		if ch == '!' {
+			return Token{Type: ERROR, Value: op}
+		}
+		return Token{Type: OPERATOR, Value: op}
+	}
+	if op, ok := comparisonSymbols[ch]; ok {
+		return Token{Type: OPERATOR, Value: op}
+	}
+
+	if s.isNumber(ch) {
 		s.Unread()
 		return s.ScanNumber()
 	} else if unicode.IsLetter(ch) {
 		s.Unread()
 		return s.ScanWord()
 	} else if isOperator(ch) {
-		return Token{OPERATOR, string(ch)}
+		if ch == '@' {
+			ch = '∠'
+		}
+		return Token{Type: OPERATOR, Value: string(ch)}
 	} else if isWhitespace(ch) {
 		s.Unread()
 		return s.ScanWhitespace()
@@ -84,14 +128,24 @@ func (s *Scanner) Scan() Token {
 
 	switch ch {
 	case eof:
-		return Token{EOF, ""}
+		return Token{Type: EOF, Value: ""}
 	case '(':
-		return Token{LPAREN, "("}
+		return Token{Type: LPAREN, Value: "("}
 	case ')':
-		return Token{RPAREN, ")"}
+		return Token{Type: RPAREN, Value: ")"}
+	case '[':
+		return Token{Type: LBRACKET, Value: "["}
+	case ']':
+		return Token{Type: RBRACKET, Value: "]"}
+	case '{':
+		return Token{Type: LBRACKET, Value: "{"}
+	case '}':
+		return Token{Type: RBRACKET, Value: "}"}
+	case ',', ';':
+		return Token{Type: SEPARATOR, Value: ","}
 	}
 
-	return Token{ERROR, string(ch)}
+	return Token{Type: ERROR, Value: string(ch)}
 }
 
 func (s *Scanner) ScanWord() Token {
@@ -109,15 +163,51 @@ func (s *Scanner) ScanWord() Token {
 		}
 	}
 	value := buf.String()
+	if bearing, ok := s.scanBearing(value); ok {
+		return bearing
+	}
+	if word := s.peekName(value); word != value {
+		for range []rune(word)[len([]rune(value)):] {
+			s.Read()
+		}
+		value = word
+	}
 	if tt, ok := s.elemNames[value]; ok {
-		return Token{tt, value}
+		return Token{Type: tt, Value: value}
 	}
 
-	return Token{ERROR, value}
+	return Token{Type: VARIABLE, Value: value}
+}
+
+// peekName returns the known name with digits like chi2cdf starting with
+// the letters of word, or word when there is none. Other words stop at the
+// digits, so x2 is still x*2
+func (s *Scanner) peekName(word string) string {
+	const maxNameLen = 16
+	name := word
+	next := []rune(word)
+	for _, ch := range s.Peek(maxNameLen) {
+		if !unicode.IsLetter(ch) && !unicode.IsDigit(ch) {
+			break
+		}
+		next = append(next, ch)
+		if _, ok := s.elemNames[string(next)]; ok {
+			name = string(next)
+		}
+	}
+	return name
 }
 
 func (s *Scanner) ScanNumber() Token {
+	if date := s.peekDate(); date != "" {
+		for range date {
+			s.Read()
+		}
+		return Token{Type: CONSTANT, Value: date}
+	}
+
 	var buf bytes.Buffer
+	marked, angle := false, false
 	for {
 		runes := s.Peek(1)
 		if len(runes) == 0 || runes[0] == eof {
@@ -125,24 +215,77 @@ func (s *Scanner) ScanNumber() Token {
 			break
 		}
 		ch := runes[0]
-		if isNumber(ch) {
+		if s.isNumber(ch) {
 			s.Read()
+			if ch == ',' {
+				ch = '.'
+			}
 			_, _ = buf.WriteRune(ch)
 			continue
 		}
-		if isDegree(ch) {
+		if s.isMarker(ch) {
 			nextRunes := s.Peek(2)
 			if len(nextRunes) > 1 && unicode.IsLetter(nextRunes[1]) {
 				break
 			}
 			s.Read()
-			_, _ = buf.WriteRune(ch)
+			_, _ = buf.WriteRune(asciiMarker(ch))
+			marked = true
+			angle = angle || isAngleSymbol(ch)
+			// the parts separated by spaces like 2h 30m are one number
+			for range s.markedPart() {
+				s.Read()
+			}
 			continue
 		}
 		break
 	}
 
-	return Token{NUMBER, degToDecString(buf.String())}
+	switch {
+	case !marked:
+		return Token{Type: NUMBER, Value: degToDecString(buf.String())}
+	case s.TimeMode || strings.ContainsRune(buf.String(), 'h'):
+		return Token{Type: NUMBER, Value: timeToDec(buf.String()).Text('f', -1), Kind: Duration}
+	case !angle:
+		// the evaluation decides whether d, m and s are times
+		return Token{Type: NUMBER, Value: degToDecString(buf.String()), Kind: Angle, Marked: buf.String()}
+	}
+	return Token{Type: NUMBER, Value: degToDecString(buf.String()), Kind: Angle}
+}
+
+// peekDate returns the date literal like 2026-10-18 starting the number or
+// an empty string
+func (s *Scanner) peekDate() string {
+	runes := s.Peek(len(dateLayout) + 1)
+	if len(runes) < len(dateLayout) || len(runes) > len(dateLayout) && unicode.IsDigit(runes[len(dateLayout)]) {
+		return ""
+	}
+	for i, ch := range runes[:len(dateLayout)] {
+		if dateLayout[i] == '-' && ch != '-' || dateLayout[i] != '-' && !unicode.IsDigit(ch) {
+			return ""
+		}
+	}
+	return string(runes[:len(dateLayout)])
+}
+
+// markedPart returns the spaces before the next part of a number with
+// markers, like the space of 2h 30m, or an empty string when the number ends
+func (s *Scanner) markedPart() string {
+	const maxPartLen = 32
+	runes := s.Peek(maxPartLen)
+	i := 0
+	for i < len(runes) && isWhitespace(runes[i]) {
+		i++
+	}
+	j := i
+	for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+		j++
+	}
+	if i == 0 || j == i || j == len(runes) || !s.isMarker(runes[j]) ||
+		j+1 < len(runes) && unicode.IsLetter(runes[j+1]) {
+		return ""
+	}
+	return string(runes[:i])
 }
 
 func (s *Scanner) ScanWhitespace() Token {
@@ -160,19 +303,48 @@ func (s *Scanner) ScanWhitespace() Token {
 		}
 	}
 
-	return Token{WHITESPACE, buf.String()}
+	return Token{Type: WHITESPACE, Value: buf.String()}
 }
 
-func isNumber(r rune) bool {
-	return unicode.IsDigit(r) || r == '.'
+func (s *Scanner) isNumber(r rune) bool {
+	return unicode.IsDigit(r) || r == '.' || (r == ',' && s.DecimalComma)
 }
 
 func isDegree(r rune) bool {
-	return r == 'd' || r == '\'' || r == 'm' || r == '"' || r == 's'
+	return r == 'd' || r == '\'' || r == 'm' || r == '"' || r == 's' || r == '°' || r == '′' || r == '″'
+}
+
+// asciiMarker replaces the symbols ° ′ ″ by the markers d ' "
+func asciiMarker(r rune) rune {
+	switch r {
+	case '°':
+		return 'd'
+	case '′':
+		return '\''
+	case '″':
+		return '"'
+	}
+	return r
 }
 
+// isAngleSymbol reports whether the marker is only found in angles
+func isAngleSymbol(r rune) bool {
+	return r == '\'' || r == '"' || r == '°' || r == '′' || r == '″'
+}
+
+// isMarker reports whether r marks the unit of a number, the degree markers
+// and the h of hours
+func (s *Scanner) isMarker(r rune) bool {
+	return isDegree(r) || r == 'h'
+}
+
+// comparisons are the operators that may be followed by =
+var comparisons = map[rune]string{'<': "<", '>': ">", '=': "=", '!': "!"}
+
+var comparisonSymbols = map[rune]string{'≤': "<=", '≥': ">=", '≠': "!="}
+
 func isOperator(r rune) bool {
-	return r == '+' || r == '-' || r == '*' || r == '/' || r == '^'
+	return r == '+' || r == '-' || r == '*' || r == '/' || r == '^' || r == '∠' || r == '@' || r == '='
 }
 
 func isWhitespace(ch rune) bool {
@@ -32,4 +32,107 @@ func TestScan(t *testing.T) {
 	assert("45d20.5m", "45d20.5'")
 	assert("45d20m15", "45d20'15")
 	assert(`45d20m15s`, `45d20'15"`)
+
+	assert(`45°`, "45d")
+	assert(`45°20′`, "45d20'")
+	assert(`45°20′15.5″`, `45d20'15.5"`)
+	assert(`45° 20′ 15″`, `45d20'15"`)
+}
+
+func TestScanWordWithDigits(t *testing.T) {
+	names := map[string]TokenType{"chi2cdf": FUNCTION, "x": VARIABLE}
+	tests := []struct {
+		text string
+		want []Token
+	}{
+		{"chi2cdf(", []Token{{Type: FUNCTION, Value: "chi2cdf"}, {Type: LPAREN, Value: "("}}},
+		{"x2", []Token{{Type: VARIABLE, Value: "x"}, {Type: NUMBER, Value: "2"}}},
+		{"chi2", []Token{{Type: VARIABLE, Value: "chi"}, {Type: NUMBER, Value: "2"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			scanner := NewScanner(strings.NewReader(tt.text), names)
+			for _, want := range tt.want {
+				if got := scanner.Scan(); got != want {
+					t.Errorf("Scan() = %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestScanTime(t *testing.T) {
+	tests := []struct {
+		text     string
+		timeMode bool
+		want     Token
+	}{
+		{"2h 35m 10s", true, Token{Type: NUMBER, Value: timeToDec("2h35m10s").Text('f', -1), Kind: Duration}},
+		{"2h30", true, Token{Type: NUMBER, Value: "2.5", Kind: Duration}},
+		{"90d", true, Token{Type: NUMBER, Value: "2160", Kind: Duration}},
+		{"45m", true, Token{Type: NUMBER, Value: "0.75", Kind: Duration}},
+		{"45", true, Token{Type: NUMBER, Value: "45"}},
+		{`45d 30'`, false, Token{Type: NUMBER, Value: "45.5", Kind: Angle}},
+		{"45d 30", false, Token{Type: NUMBER, Value: "45", Kind: Angle, Marked: "45d"}},
+		{"2026-10-18", false, Token{Type: CONSTANT, Value: "2026-10-18"}},
+		{"2026-10-180", false, Token{Type: NUMBER, Value: "2026"}},
+		{"2026-10", false, Token{Type: NUMBER, Value: "2026"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			scanner := NewScanner(strings.NewReader(tt.text), nil)
+			scanner.TimeMode = tt.timeMode
+			if got := scanner.Scan(); got != tt.want {
+				t.Errorf("Scan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanMarked(t *testing.T) {
+	tests := []struct {
+		text string
+		want Token
+	}{
+		{"45d30m", Token{Type: NUMBER, Value: "45.5", Kind: Angle, Marked: "45d30m"}},
+		{"45m", Token{Type: NUMBER, Value: "0.75", Kind: Angle, Marked: "45m"}},
+		{"1d 30m 15s", Token{Type: NUMBER, Value: degToDecString("1d30m15s"), Kind: Angle, Marked: "1d30m15s"}},
+		{"1d 30'", Token{Type: NUMBER, Value: "1.5", Kind: Angle}},
+		{"45°", Token{Type: NUMBER, Value: "45", Kind: Angle}},
+		{"1d 2h", Token{Type: NUMBER, Value: "26", Kind: Duration}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			scanner := NewScanner(strings.NewReader(tt.text), nil)
+			if got := scanner.Scan(); got != tt.want {
+				t.Errorf("Scan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanBearing(t *testing.T) {
+	names := map[string]TokenType{"n": VARIABLE, "e": CONSTANT}
+	tests := []struct {
+		text string
+		want []Token
+	}{
+		{"n45d30'e", []Token{{Type: NUMBER, Value: "45.5", Kind: Angle}}},
+		{"s45w", []Token{{Type: NUMBER, Value: "225", Kind: Angle}}},
+		{"n45e+1", []Token{{Type: NUMBER, Value: "45", Kind: Angle}, {Type: OPERATOR, Value: "+"}}},
+		{"n45ex", []Token{{Type: VARIABLE, Value: "n"}, {Type: NUMBER, Value: "45"}}},
+		{"n45", []Token{{Type: VARIABLE, Value: "n"}, {Type: NUMBER, Value: "45"}}},
+		{"n95e", []Token{{Type: ERROR, Value: "N95E"}}},
+		{"s45°30′w", []Token{{Type: NUMBER, Value: "225.5", Kind: Angle}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			scanner := NewScanner(strings.NewReader(tt.text), names)
+			for _, want := range tt.want {
+				if got := scanner.Scan(); got != want {
+					t.Errorf("Scan() = %v, want %v", got, want)
+				}
+			}
+		})
+	}
 }
@@ -1,38 +1,152 @@
 package esolver
 
+import "strconv"
+
+// ShuntingYard converts the infix expression to postfix. Functions are
+// prefix operators: with parentheses they apply to the whole group and
+// without them they bind tighter than any operator, so sin30^2 is
+// sin(30)^2. Operators found where an operand is expected are converted
+// to UNARY tokens. Function calls with parentheses and brackets are
+// preceded by an ARGS token with the number of arguments, each argument of
+// a lazy function is enclosed by LQUOTE and RQUOTE
 func ShuntingYard(s Stack) Stack {
+	postfix, _ := shuntingYard(s, valueFuncs)
+	return postfix
+}
+
+// ShuntingYardIndex is ShuntingYard returning also for each postfix token
+// the index of the infix token it comes from, -1 for the tokens inserted
+// like ARGS
+func ShuntingYardIndex(s Stack) (Stack, []int) {
+	return shuntingYard(s, valueFuncs)
+}
+
+// shuntingYard converts to postfix quoting the arguments of the lazy
+// functions found in funcs, the solver passes its own functions
+func shuntingYard(s Stack, funcs map[string]ValueFunction) (Stack, []int) {
 	postfix := Stack{}
+	var index []int // infix index of each postfix token
+	emit := func(t Token, i int) {
+		postfix.Push(t)
+		index = append(index, i)
+	}
 	operators := Stack{}
-	for _, v := range s.Values {
+	var opIndex []int // infix index of each operator
+	pushOperator := func(t Token, i int) {
+		operators.Push(t)
+		opIndex = append(opIndex, i)
+	}
+	popOperator := func() (Token, int) {
+		i := opIndex[len(opIndex)-1]
+		opIndex = opIndex[:len(opIndex)-1]
+		return operators.Pop(), i
+	}
+	type group struct {
+		args int
+		lazy bool
+	}
+	var groups []group // for each open parenthesis or bracket
+	lastType := TokenType(-1)
+	for i, v := range s.Values {
+		if v.Type == OPERATOR && isPrefixPosition(lastType) {
+			if _, ok := unaryData[v.Value]; ok {
+				v.Type = UNARY
+			}
+		}
+		prevType := lastType
+		lastType = v.Type
+
 		switch v.Type {
 		case OPERATOR:
 			for !operators.IsEmpty() {
-				val := v.Value
-				top := operators.Peek().Value
-				if (oprData[val].prec <= oprData[top].prec && !oprData[val].rAsoc) ||
-					(oprData[val].prec < oprData[top].prec && oprData[val].rAsoc) {
-					postfix.Push(operators.Pop())
+				top := operators.Peek()
+				if isOpenGroup(top.Type) {
+					break
+				}
+				prec, topPrec := precedence(v), precedence(top)
+				if (prec <= topPrec && !oprData[v.Value].rAsoc) ||
+					(prec < topPrec && oprData[v.Value].rAsoc) {
+					emit(popOperator())
 					continue
 				}
 				break
 			}
-			operators.Push(v)
-		case LPAREN:
-			operators.Push(v)
-		case RPAREN:
-			for i := operators.Length() - 1; i >= 0; i-- {
-				if operators.Values[i].Type != LPAREN {
-					postfix.Push(operators.Pop())
-					continue
-				} else {
-					operators.Pop()
+			pushOperator(v, i)
+		case UNARY, FUNCTION:
+			pushOperator(v, i)
+		case LPAREN, LBRACKET:
+			lazy := v.Type == LPAREN && prevType == FUNCTION && funcs[operators.Peek().Value].Lazy
+			if lazy {
+				emit(Token{Type: LQUOTE, Value: "{"}, -1)
+			}
+			pushOperator(v, i)
+			groups = append(groups, group{1, lazy})
+		case SEPARATOR:
+			for !operators.IsEmpty() && !isOpenGroup(operators.Peek().Type) {
+				emit(popOperator())
+			}
+			if len(groups) > 0 {
+				groups[len(groups)-1].args++
+				if groups[len(groups)-1].lazy {
+					emit(Token{Type: RQUOTE, Value: "}"}, -1)
+					emit(Token{Type: LQUOTE, Value: "{"}, -1)
+				}
+			}
+		case RPAREN, RBRACKET:
+			g := group{}
+			if len(groups) > 0 {
+				g = groups[len(groups)-1]
+				groups = groups[:len(groups)-1]
+			}
+			n := g.args
+			if isOpenGroup(prevType) {
+				n = 0
+			}
+			for !operators.IsEmpty() {
+				t, j := popOperator()
+				if isOpenGroup(t.Type) {
 					break
 				}
+				emit(t, j)
+			}
+			if g.lazy {
+				emit(Token{Type: RQUOTE, Value: "}"}, -1)
+			}
+			if v.Type == RBRACKET {
+				emit(Token{Type: ARGS, Value: strconv.Itoa(n)}, -1)
+				emit(v, i)
+			} else if !operators.IsEmpty() && operators.Peek().Type == FUNCTION {
+				emit(Token{Type: ARGS, Value: strconv.Itoa(n)}, -1)
+				emit(popOperator())
 			}
 		default:
-			postfix.Push(v)
+			emit(v, i)
 		}
 	}
-	operators.EmptyInto(&postfix)
-	return postfix
+	for !operators.IsEmpty() {
+		emit(popOperator())
+	}
+	return postfix, index
+}
+
+func isOpenGroup(t TokenType) bool {
+	return t == LPAREN || t == LBRACKET
+}
+
+func isPrefixPosition(lastType TokenType) bool {
+	return lastType == -1 || lastType == OPERATOR || lastType == UNARY ||
+		lastType == LPAREN || lastType == LBRACKET || lastType == SEPARATOR || lastType == FUNCTION
+}
+
+func precedence(t Token) int {
+	switch t.Type {
+	case FUNCTION:
+		return funcPrec
+	case UNARY:
+		if t.Value == "not" {
+			return notPrec
+		}
+		return unaryPrec
+	}
+	return oprData[t.Value].prec
 }
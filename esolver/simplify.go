@@ -0,0 +1,303 @@
+package esolver
+
+import (
+	"math/big"
+	"slices"
+)
+
+// maxFoldedPower limits the integer powers of numbers folded to a constant
+const maxFoldedPower = 64
+
+// term is an addend of a sum, the product of a rational coefficient and
+// the factors
+type term struct {
+	coef    *big.Rat
+	factors []factor
+}
+
+// factor is the base raised to the exponent in a product
+type factor struct {
+	base Node
+	exp  Node
+}
+
+// simplify rewrites the tree folding the constants, removing the identity
+// elements and collecting the like terms of sums and the like factors of
+// products
+func simplify(n Node) Node {
+	return Rewrite(n, func(n Node) Node {
+		switch x := n.(type) {
+		case *UnaryOp:
+			if x.Op == "+" {
+				return x.X
+			}
+			return simplifySum(x)
+		case *BinaryOp:
+			switch x.Op {
+			case "+", "-":
+				return simplifySum(x)
+			case "*", "/":
+				return simplifyProduct(x)
+			case "^":
+				return simplifyPow(x)
+			}
+		}
+		return n
+	})
+}
+
+// simplifySum collects the terms with the same factors adding their
+// coefficients, the constant term goes last
+func simplifySum(n Node) Node {
+	var terms []term
+	addTerms(n, 1, &terms)
+
+	var collected []term
+	constant := new(big.Rat)
+	index := make(map[string]int)
+	for _, t := range terms {
+		if len(t.factors) == 0 {
+			constant.Add(constant, t.coef)
+			continue
+		}
+		key := buildProduct(big.NewRat(1, 1), t.factors).String()
+		if i, ok := index[key]; ok {
+			collected[i].coef.Add(collected[i].coef, t.coef)
+			continue
+		}
+		index[key] = len(collected)
+		collected = append(collected, term{new(big.Rat).Set(t.coef), t.factors})
+	}
+	collected = slices.DeleteFunc(collected, func(t term) bool { return t.coef.Sign() == 0 })
+	if constant.Sign() != 0 || len(collected) == 0 {
+		collected = append(collected, term{coef: constant})
+	}
+
+	sum := buildProduct(collected[0].coef, collected[0].factors)
+	for _, t := range collected[1:] {
+		if t.coef.Sign() < 0 {
+			sum = &BinaryOp{"-", sum, buildProduct(new(big.Rat).Neg(t.coef), t.factors)}
+		} else {
+			sum = &BinaryOp{"+", sum, buildProduct(t.coef, t.factors)}
+		}
+	}
+	return sum
+}
+
+func addTerms(n Node, sign int64, terms *[]term) {
+	switch x := n.(type) {
+	case *BinaryOp:
+		switch x.Op {
+		case "+":
+			addTerms(x.X, sign, terms)
+			addTerms(x.Y, sign, terms)
+			return
+		case "-":
+			addTerms(x.X, sign, terms)
+			addTerms(x.Y, -sign, terms)
+			return
+		}
+	case *UnaryOp:
+		if x.Op == "-" {
+			addTerms(x.X, -sign, terms)
+			return
+		}
+	}
+	coef, factors := splitProduct(n)
+	*terms = append(*terms, term{coef.Mul(coef, big.NewRat(sign, 1)), factors})
+}
+
+// simplifyProduct multiplies the numbers and adds the exponents of the
+// factors with the same base
+func simplifyProduct(n Node) Node {
+	return buildProduct(splitProduct(n))
+}
+
+func splitProduct(n Node) (*big.Rat, []factor) {
+	coef := big.NewRat(1, 1)
+	var factors []factor
+	addFactors(n, false, coef, &factors)
+
+	var collected []factor
+	index := make(map[string]int)
+	for _, f := range factors {
+		key := f.base.String()
+		if i, ok := index[key]; ok {
+			collected[i].exp = simplifySum(&BinaryOp{"+", collected[i].exp, f.exp})
+			continue
+		}
+		index[key] = len(collected)
+		collected = append(collected, f)
+	}
+	collected = slices.DeleteFunc(collected, func(f factor) bool { return isNumber(f.exp, 0) })
+
+	// variables first, the functions after them
+	slices.SortStableFunc(collected, func(a, b factor) int {
+		return factorRank(a) - factorRank(b)
+	})
+	return coef, collected
+}
+
+func addFactors(n Node, inverse bool, coef *big.Rat, factors *[]factor) {
+	switch x := n.(type) {
+	case *Number:
+		r := rational(x)
+		if !inverse {
+			coef.Mul(coef, r)
+			return
+		}
+		if r.Sign() != 0 {
+			coef.Quo(coef, r)
+			return
+		}
+	case *UnaryOp:
+		if x.Op == "-" {
+			coef.Neg(coef)
+			addFactors(x.X, inverse, coef, factors)
+			return
+		}
+	case *BinaryOp:
+		switch x.Op {
+		case "*":
+			addFactors(x.X, inverse, coef, factors)
+			addFactors(x.Y, inverse, coef, factors)
+			return
+		case "/":
+			addFactors(x.X, inverse, coef, factors)
+			addFactors(x.Y, !inverse, coef, factors)
+			return
+		case "^":
+			exp := x.Y
+			if inverse {
+				exp = simplifySum(&UnaryOp{"-", exp})
+			}
+			*factors = append(*factors, factor{x.X, exp})
+			return
+		}
+	}
+	exp := number(big.NewRat(1, 1))
+	if inverse {
+		exp = number(big.NewRat(-1, 1))
+	}
+	*factors = append(*factors, factor{n, exp})
+}
+
+func factorRank(f factor) int {
+	if _, ok := f.base.(*Ident); ok {
+		return 0
+	}
+	return 1
+}
+
+// buildProduct creates the node coef*factors, the factors with a negative
+// exponent go to the denominator
+func buildProduct(coef *big.Rat, factors []factor) Node {
+	if coef.Sign() == 0 || len(factors) == 0 {
+		return number(coef)
+	}
+	abs := new(big.Rat).Abs(coef)
+
+	var num, den []Node
+	if !isOne(abs.Num()) {
+		num = append(num, &Number{Value: new(big.Float).SetPrec(floatPrec).SetInt(abs.Num())})
+	}
+	if !isOne(abs.Denom()) {
+		den = append(den, &Number{Value: new(big.Float).SetPrec(floatPrec).SetInt(abs.Denom())})
+	}
+	for _, f := range factors {
+		if neg, ok := f.exp.(*UnaryOp); ok && neg.Op == "-" {
+			den = append(den, power(f.base, neg.X))
+		} else {
+			num = append(num, power(f.base, f.exp))
+		}
+	}
+	if len(num) == 0 {
+		num = append(num, number(big.NewRat(1, 1)))
+	}
+	if coef.Sign() < 0 {
+		num[0] = &UnaryOp{"-", num[0]}
+	}
+
+	n := product(num)
+	if len(den) > 0 {
+		n = &BinaryOp{"/", n, product(den)}
+	}
+	return n
+}
+
+func product(factors []Node) Node {
+	n := factors[0]
+	for _, f := range factors[1:] {
+		n = &BinaryOp{"*", n, f}
+	}
+	return n
+}
+
+func power(base, exp Node) Node {
+	if isNumber(exp, 1) {
+		return base
+	}
+	return &BinaryOp{"^", base, exp}
+}
+
+// simplifyPow folds the integer powers of numbers and removes the
+// exponents 0 and 1
+func simplifyPow(n *BinaryOp) Node {
+	base, exp := n.X, n.Y
+	switch {
+	case isNumber(exp, 0), isNumber(base, 1):
+		return number(big.NewRat(1, 1))
+	case isNumber(exp, 1):
+		return base
+	}
+
+	e, ok := exp.(*Number)
+	if !ok {
+		if neg, isNeg := exp.(*UnaryOp); isNeg && neg.Op == "-" {
+			e, ok = neg.X.(*Number)
+		}
+	}
+	if !ok {
+		return n
+	}
+	r := rational(e)
+	if exp != Node(e) {
+		r.Neg(r)
+	}
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return n
+	}
+	k := r.Num().Int64()
+
+	switch x := base.(type) {
+	case *Number:
+		b := rational(x)
+		if k < -maxFoldedPower || k > maxFoldedPower || (b.Sign() == 0 && k < 0) {
+			return n
+		}
+		result := big.NewRat(1, 1)
+		for i := int64(0); i < k || i < -k; i++ {
+			result.Mul(result, b)
+		}
+		if k < 0 {
+			result.Inv(result)
+		}
+		return number(result)
+	case *BinaryOp:
+		// (x^a)^k = x^(a*k) for an integer k
+		if x.Op == "^" {
+			return simplifyPow(&BinaryOp{"^", x.X, simplifyProduct(&BinaryOp{"*", x.Y, exp})})
+		}
+	}
+	return n
+}
+
+// isNumber reports whether the node is the integer k
+func isNumber(n Node, k int64) bool {
+	x, ok := n.(*Number)
+	return ok && x.Value.Cmp(new(big.Float).SetInt64(k)) == 0
+}
+
+func isOne(x *big.Int) bool {
+	return x.IsInt64() && x.Int64() == 1
+}
@@ -2,23 +2,94 @@ package esolver
 
 import (
 	"errors"
+	"fmt"
+	"maps"
 	"math"
 	"math/big"
+	"strconv"
 	"strings"
+	"time"
 )
 
 var errInvalidExpression = errors.New("invalid expression")
 
+// ErrDeadline is returned by the evaluations stopped by the deadline
+var ErrDeadline = errors.New("the evaluation took too long")
+
 var oprData = map[string]struct {
 	prec  int
 	rAsoc bool // true = right // false = left
-	fx    func(x, y *big.Float) *big.Float
+	fx    func(x, y *big.Float) (Value, error)
+	cfx   func(x, y *Complex) (*Complex, error)
 }{
-	"^": {4, true, func(x, y *big.Float) *big.Float { return bigPow(x, y) }},
-	"*": {3, false, func(x, y *big.Float) *big.Float { z := new(big.Float); return z.Mul(x, y) }},
-	"/": {3, false, func(x, y *big.Float) *big.Float { z := new(big.Float); return z.Quo(x, y) }},
-	"+": {2, false, func(x, y *big.Float) *big.Float { z := new(big.Float); return z.Add(x, y) }},
-	"-": {2, false, func(x, y *big.Float) *big.Float { z := new(big.Float); return z.Sub(x, y) }},
+	"^": {8, true, bigPowReal, complexPow},
+	"*": {6, false,
+		func(x, y *big.Float) (Value, error) { z := new(big.Float); return z.Mul(x, y), nil },
+		func(x, y *Complex) (*Complex, error) { return complexMul(x, y), nil }},
+	"/": {6, false,
+		func(x, y *big.Float) (Value, error) { z := new(big.Float); return z.Quo(x, y), nil },
+		complexQuo},
+	"∠": {7, false,
+		func(x, y *big.Float) (Value, error) { return normalize(NewPolar(x, y)), nil },
+		nil},
+	"+": {5, false,
+		func(x, y *big.Float) (Value, error) { z := new(big.Float); return z.Add(x, y), nil },
+		func(x, y *Complex) (*Complex, error) { return complexAdd(x, y), nil }},
+	"-": {5, false,
+		func(x, y *big.Float) (Value, error) { z := new(big.Float); return z.Sub(x, y), nil },
+		func(x, y *Complex) (*Complex, error) { return complexSub(x, y), nil }},
+	// comparisons, the logical operators are in logicData
+	"<":   {4, false, compare("<"), nil},
+	"<=":  {4, false, compare("<="), nil},
+	">":   {4, false, compare(">"), nil},
+	">=":  {4, false, compare(">="), nil},
+	"==":  {4, false, compare("=="), nil},
+	"!=":  {4, false, compare("!="), nil},
+	"and": {2, false, nil, nil},
+	"or":  {1, false, nil, nil},
+	"=": {0, false,
+		func(x, y *big.Float) (Value, error) { return nil, errEquation },
+		func(x, y *Complex) (*Complex, error) { return nil, errEquation }},
+	// element by element operators for vectors and matrices
+	".^": {8, true, bigPowReal, complexPow},
+	".*": {6, false,
+		func(x, y *big.Float) (Value, error) { z := new(big.Float); return z.Mul(x, y), nil },
+		func(x, y *Complex) (*Complex, error) { return complexMul(x, y), nil }},
+	"./": {6, false,
+		func(x, y *big.Float) (Value, error) { z := new(big.Float); return z.Quo(x, y), nil },
+		complexQuo},
+}
+
+// unaryPrec binds prefix operators tighter than * and / but looser than ^,
+// so -2^2 is -(2^2)
+const unaryPrec = 7
+
+// notPrec binds not looser than the comparisons but tighter than and, so
+// not a < b and c is (not (a < b)) and c
+const notPrec = 3
+
+// funcPrec binds functions without parentheses tighter than any operator
+const funcPrec = 9
+
+var unaryData = map[string]func(x Value) (Value, error){
+	"-": func(x Value) (Value, error) {
+		switch v := x.(type) {
+		case *big.Float:
+			return new(big.Float).Neg(v), nil
+		case *Complex:
+			return &Complex{Re: new(big.Float).Neg(v.Re), Im: new(big.Float).Neg(v.Im)}, nil
+		case Vector, Matrix:
+			return mapElements(v, func(x *big.Float) (Value, error) { return new(big.Float).Neg(x), nil })
+		}
+		return nil, fmt.Errorf("-: %s operand not supported", typeName(x))
+	},
+	"+": func(x Value) (Value, error) {
+		if !isNumeric(x) {
+			return nil, fmt.Errorf("+: %s operand not supported", typeName(x))
+		}
+		return x, nil
+	},
+	"not": not,
 }
 
 var funcs = map[string]Function{
@@ -34,6 +105,82 @@ var funcs = map[string]Function{
 	"cbrt":  bigCbrt,
 	"ceil":  bigCeil,
 	"floor": bigFloor,
+	"arg":   bigArg,
+	"conj":  bigRe,
+	"re":    bigRe,
+	"im":    bigIm,
+}
+
+var valueFuncs = map[string]ValueFunction{
+	"dot":         {2, 2, dot, false},
+	"cross":       {2, 2, cross, false},
+	"norm":        {1, 1, norm, false},
+	"det":         {1, 1, det, false},
+	"inv":         {1, 1, inv, false},
+	"transpose":   {1, 1, transposeFunc, false},
+	"solve":       {1, 3, solve, true},
+	"integrate":   {4, 4, integrate, true},
+	"deriv":       {3, 3, deriv, true},
+	"diff":        {1, 2, diff, true},
+	"if":          {3, 3, ifFunc, true},
+	"sum":         {1, -1, sumFunc, false},
+	"mean":        {1, -1, meanFunc, false},
+	"median":      {1, -1, medianFunc, false},
+	"mode":        {1, -1, modeFunc, false},
+	"stdev":       {1, -1, stdevFunc, false},
+	"var":         {1, -1, varFunc, false},
+	"min":         {1, -1, minFunc, false},
+	"max":         {1, -1, maxFunc, false},
+	"percentile":  {2, 2, percentile, false},
+	"linreg":      {1, 2, linreg, false},
+	"ncr":         {2, 2, nCr, false},
+	"npr":         {2, 2, nPr, false},
+	"factorial":   {1, 1, factorial, false},
+	"isprime":     {1, 1, isPrime, false},
+	"factor":      {1, 1, neverStop(factorFunc), false},
+	"gcd":         {2, -1, gcd, false},
+	"lcm":         {2, -1, lcm, false},
+	"powmod":      {3, 3, powMod, false},
+	"normcdf":     {1, 3, normcdf, false},
+	"norminv":     {1, 3, norminv, false},
+	"tcdf":        {2, 2, tcdf, false},
+	"tinv":        {2, 2, tinv, false},
+	"chi2cdf":     {2, 2, chi2cdf, false},
+	"binompdf":    {3, 3, neverStop(binompdf), false},
+	"binomcdf":    {3, 3, neverStop(binomcdf), false},
+	"poissonpdf":  {2, 2, neverStop(poissonpdf), false},
+	"poissoncdf":  {2, 2, neverStop(poissoncdf), false},
+	"days":        {1, 1, days, false},
+	"bearing":     {1, 1, bearingFunc, false},
+	"azimuth":     {1, 1, azimuth, false},
+	"latdep":      {2, 2, latdep, false},
+	"inverse":     {2, 4, inverse, false},
+	"pv":          {3, 5, pv, false},
+	"fv":          {3, 5, fv, false},
+	"pmt":         {3, 5, pmt, false},
+	"nper":        {3, 5, nper, false},
+	"rate":        {3, 6, rate, false},
+	"npv":         {2, -1, npv, false},
+	"irr":         {1, 2, irr, false},
+	"simpleint":   {3, 3, simpleInterest, false},
+	"compoundint": {3, 4, compoundInterest, false},
+}
+
+// stoppable are the functions with long loops, they stop when the deadline
+// of the evaluation passes
+var stoppable = map[string]func(args []Value, stop func() bool) (Value, error){
+	"factor":     factorFunc,
+	"binompdf":   binompdf,
+	"binomcdf":   binomcdf,
+	"poissonpdf": poissonpdf,
+	"poissoncdf": poissoncdf,
+}
+
+// neverStop calls a function with long loops without a deadline
+func neverStop(f func(args []Value, stop func() bool) (Value, error)) func(args []Value) (Value, error) {
+	return func(args []Value) (Value, error) {
+		return f(args, func() bool { return false })
+	}
 }
 
 var consts = map[string]ConstFunction{
@@ -47,16 +194,48 @@ var consts = map[string]ConstFunction{
 	"in":      func() *big.Float { return big.NewFloat(25.4) },
 }
 
+var complexConsts = map[string]func() *Complex{
+	"i": func() *Complex { return NewComplex(new(big.Float), big.NewFloat(1)) },
+	"j": func() *Complex { return NewComplex(new(big.Float), big.NewFloat(1)) },
+}
+
 type ESolver interface {
 	Solve(s string) (*big.Float, error)
 	SolveStack(stack Stack) (*big.Float, error)
 	SolvePostfix(tokens Stack) (*big.Float, error)
+	Eval(s string) (Value, error)
+	EvalStack(stack Stack) (Value, error)
+	EvalPostfix(tokens Stack) (Value, error)
+	EvalKind(stack Stack) (Value, Kind, error)
 	ParseExpression(s string) (Stack, error)
+	ParseWarnings(s string) (Stack, []string, error)
 	AddConstant(name string, constCreator ConstFunction)
+	AddValue(name string, valueCreator func() Value)
+	SetKind(name string, kind func() Kind)
+	AddFunction(name string, f ValueFunction) error
+	DefineFunction(name string, params []string, body string) error
+	SetComplexMode(enabled bool)
+	ComplexMode() bool
+	SetStrictness(s Strictness)
+	Strictness() Strictness
+	SetAngleUnit(u AngleUnit)
+	AngleUnit() AngleUnit
+	SetDeadline(t time.Time)
+	Names() map[string]TokenType
+	Spans(s string) []Span
 }
 type esolver struct {
-	elemNames  map[string]TokenType
-	userConsts map[string]ConstFunction
+	elemNames   map[string]TokenType
+	userConsts  map[string]func() Value
+	userKinds   map[string]func() Kind
+	valueFuncs  map[string]ValueFunction
+	complexMode bool
+	strictness  Strictness
+	angleUnit   AngleUnit
+	// depth is the number of nested calls of the user functions
+	depth int
+	// deadline stops the evaluations, the zero time never stops them
+	deadline time.Time
 }
 
 func New() ESolver {
@@ -65,145 +244,549 @@ func New() ESolver {
 		elemNames[k] = FUNCTION
 	}
 
+	for k := range valueFuncs {
+		elemNames[k] = FUNCTION
+	}
+
 	for k := range consts {
 		elemNames[k] = CONSTANT
 	}
+
+	for k := range logicData {
+		elemNames[k] = OPERATOR
+	}
+	elemNames["not"] = OPERATOR
+
+	for k := range complexConsts {
+		elemNames[k] = CONSTANT
+	}
+	for k := range boolConsts {
+		elemNames[k] = CONSTANT
+	}
+	for k := range dateConsts {
+		elemNames[k] = CONSTANT
+	}
 	return &esolver{
 		elemNames:  elemNames,
-		userConsts: make(map[string]ConstFunction),
+		userConsts: make(map[string]func() Value),
+		userKinds:  make(map[string]func() Kind),
+		valueFuncs: maps.Clone(valueFuncs),
 	}
 }
 
 func (e *esolver) Solve(s string) (*big.Float, error) {
-	stack, err := e.ParseExpression(s)
+	v, err := e.Eval(s)
 	if err != nil {
 		return nil, err
 	}
-
-	return e.SolveStack(stack)
+	return Real(v)
 }
 
 func (e *esolver) SolveStack(stack Stack) (*big.Float, error) {
-	stack = ShuntingYard(stack)
+	v, err := e.EvalStack(stack)
+	if err != nil {
+		return nil, err
+	}
+	return Real(v)
+}
 
-	result, err := e.SolvePostfix(stack)
+// SolvePostfix evaluates and returns the answer of the expression converted to postfix
+func (e *esolver) SolvePostfix(tokens Stack) (*big.Float, error) {
+	v, err := e.EvalPostfix(tokens)
+	if err != nil {
+		return nil, err
+	}
+	return Real(v)
+}
 
+// Eval parses and evaluates the expression, the result may be complex
+func (e *esolver) Eval(s string) (Value, error) {
+	stack, err := e.ParseExpression(s)
 	if err != nil {
 		return nil, err
 	}
 
-	return result, nil
+	return e.EvalStack(stack)
 }
 
-// SolvePostfix evaluates and returns the answer of the expression converted to postfix
-func (e *esolver) SolvePostfix(tokens Stack) (*big.Float, error) {
-	stack := Stack{}
-	funcStack := Stack{}
+// EvalStack evaluates the expression parsed by ParseExpression
+func (e *esolver) EvalStack(stack Stack) (Value, error) {
+	postfix, _ := shuntingYard(stack, e.valueFuncs)
+	return e.EvalPostfix(postfix)
+}
+
+// EvalKind evaluates the expression parsed by ParseExpression returning
+// the kind of the result too, an angle or a duration
+func (e *esolver) EvalKind(stack Stack) (Value, Kind, error) {
+	postfix, _ := shuntingYard(stack, e.valueFuncs)
+	return e.evalKind(postfix, nil)
+}
+
+// EvalPostfix evaluates the expression converted to postfix
+func (e *esolver) EvalPostfix(tokens Stack) (Value, error) {
+	return e.evalPostfix(tokens, nil)
+}
+
+// evalPostfix evaluates the expression, scope holds the variables bound by
+// lazy functions and shadows the constants with the same name
+func (e *esolver) evalPostfix(tokens Stack, scope map[string]Value) (Value, error) {
+	v, _, err := e.evalKind(tokens, scope)
+	return v, err
+}
+
+// evalKind evaluates the expression keeping the kind of each value of the
+// stack, so the kind of the result follows the operators and functions
+func (e *esolver) evalKind(tokens Stack, scope map[string]Value) (result Value, kind Kind, err error) {
+	defer func() {
+		// big.Float panics on operations like 0/0 or Inf-Inf
+		if r := recover(); r != nil {
+			nan, ok := r.(big.ErrNaN)
+			if !ok {
+				panic(r)
+			}
+			result, kind, err = nil, Plain, nan
+		}
+	}()
 
-	for _, v := range tokens.Values {
+	var stack []Value
+	var kinds []Kind
+	// times are the values with the markers d, m and s read as times, nil
+	// for the values without them
+	var times []*marked
+	push := func(v Value, k Kind) {
+		stack = append(stack, v)
+		kinds = append(kinds, k)
+		times = append(times, nil)
+	}
+	pushMarked := func(v Value, k Kind, t *marked) {
+		push(v, k)
+		times[len(times)-1] = t
+	}
+	popArgs := func(n int) ([]Value, []Kind, []*marked, error) {
+		if n > len(stack) {
+			return nil, nil, nil, errInvalidExpression
+		}
+		args := make([]Value, n)
+		for i, v := range stack[len(stack)-n:] {
+			args[i] = operand(v)
+		}
+		argKinds := append([]Kind(nil), kinds[len(kinds)-n:]...)
+		argTimes := append([]*marked(nil), times[len(times)-n:]...)
+		stack = stack[:len(stack)-n]
+		kinds = kinds[:len(kinds)-n]
+		times = times[:len(times)-n]
+		return args, argKinds, argTimes, nil
+	}
+	nArgs := 1
+
+	for i := 0; i < len(tokens.Values); i++ {
+		if e.expired() {
+			return nil, Plain, ErrDeadline
+		}
+		v := tokens.Values[i]
 		switch v.Type {
 		case NUMBER:
-			if funcStack.IsEmpty() {
-				stack.Push(v)
-			} else {
-				x, _, err := big.ParseFloat(v.Value, 10, 256, big.ToNearestEven)
-				if err != nil {
-					return nil, err
-				}
-				for !funcStack.IsEmpty() {
-					f := funcs[funcStack.Pop().Value]
-					x = f(x)
+			x, err := parseNumber(v.Value)
+			if err != nil {
+				return nil, Plain, err
+			}
+			if v.Marked != "" {
+				pushMarked(x, v.Kind, &marked{timeToDec(v.Marked), strings.ContainsRune(v.Marked, 'd')})
+				break
+			}
+			push(x, v.Kind)
+		case CONSTANT, VARIABLE:
+			x, ok := scope[v.Value]
+			k := valueKind(x)
+			if !ok {
+				if x, ok = e.findConst(v.Value); !ok {
+					return nil, Plain, fmt.Errorf("unknown name %q", v.Value)
 				}
-				stack.Push(Token{NUMBER, x.Text('f', -1)})
+				k = e.constKind(v.Value, x)
+			}
+			push(x, k)
+		case LQUOTE:
+			end := matchQuote(tokens.Values, i)
+			push(&Expr{
+				postfix: Stack{tokens.Values[i+1 : end]},
+				solver:  e,
+				scope:   scope,
+			}, Plain)
+			i = end
+		case ARGS:
+			if nArgs, err = strconv.Atoi(v.Value); err != nil {
+				return nil, Plain, err
 			}
 		case FUNCTION:
-			funcStack.Push(v)
-		case CONSTANT:
-			c, ok := e.findConst(v.Value)
-			if !ok {
+			args, argKinds, argTimes, err := popArgs(nArgs)
+			nArgs = 1
+			if err != nil {
+				return nil, Plain, err
+			}
+			if durationArgs[v.Value] {
+				readTimes(args, argKinds, argTimes)
+			}
+			x, err := e.callFunc(v.Value, args, argKinds)
+			if err != nil {
+				return nil, Plain, err
+			}
+			push(x, funcKind(v.Value, argKinds, e.angleUnit))
+		case RBRACKET:
+			items, itemKinds, _, err := popArgs(nArgs)
+			nArgs = 1
+			if err != nil {
+				return nil, Plain, err
+			}
+			x, err := newArray(items)
+			if err != nil {
+				return nil, Plain, err
+			}
+			push(x, combineKinds(itemKinds...))
+		case UNARY:
+			args, argKinds, argTimes, err := popArgs(1)
+			if err != nil {
+				return nil, Plain, err
+			}
+			x, err := unaryData[v.Value](args[0])
+			if err != nil {
+				return nil, Plain, err
+			}
+			if v.Value == "not" {
+				push(x, Plain)
 				break
 			}
-			x := c()
-
-			if funcStack.IsEmpty() {
-				stack.Push(Token{NUMBER, x.Text('f', -1)})
-			} else {
-				for !funcStack.IsEmpty() {
-					f := funcs[funcStack.Pop().Value]
-					x = f(x)
+			var t *marked
+			if argTimes[0] != nil {
+				hours, err := unaryData[v.Value](argTimes[0].hours)
+				if err != nil {
+					return nil, Plain, err
 				}
-				stack.Push(Token{NUMBER, x.Text('f', -1)})
+				t = &marked{hours, argTimes[0].days}
 			}
-
+			pushMarked(x, argKinds[0], t)
 		case OPERATOR:
-			f := oprData[v.Value].fx
-
-			yVal, _, err := big.ParseFloat(stack.Pop().Value, 10, 256, big.ToNearestEven)
+			args, argKinds, argTimes, err := popArgs(2)
 			if err != nil {
-				return nil, err
+				return nil, Plain, err
 			}
-
-			xVal, _, err := big.ParseFloat(stack.Pop().Value, 10, 256, big.ToNearestEven)
+			if v.Value == "∠" && e.angleUnit == Radians && argKinds[1] != Angle {
+				// NewPolar takes degrees, the angles like 30d are not converted
+				if args[1], err = e.applyOperator("*", args[1], big.NewFloat(radToDeg)); err != nil {
+					return nil, Plain, err
+				}
+			}
+			x, k, t, err := e.markedOperator(v.Value, args, argKinds, argTimes)
 			if err != nil {
-				return nil, err
+				return nil, Plain, err
 			}
+			pushMarked(x, k, t)
+		}
+	}
+	if len(stack) != 1 {
+		return nil, Plain, errInvalidExpression
+	}
+	return stack[0], kinds[0], nil
+}
+
+// matchQuote returns the position of the RQUOTE closing the LQUOTE at start
+func matchQuote(tokens []Token, start int) int {
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		switch tokens[i].Type {
+		case LQUOTE:
+			depth++
+		case RQUOTE:
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(tokens) - 1
+}
+
+// quoted reports whether the arguments were quoted for a lazy function, a
+// function without parentheses receives its argument evaluated
+func quoted(args []Value) bool {
+	for _, v := range args {
+		if _, ok := v.(*Expr); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *esolver) callFunc(name string, args []Value, kinds []Kind) (Value, error) {
+	if f, ok := e.valueFuncs[name]; ok {
+		if len(args) < f.MinArgs || (f.MaxArgs >= 0 && len(args) > f.MaxArgs) {
+			return nil, fmt.Errorf("%s: wrong number of arguments", name)
+		}
+		if f.Lazy && !quoted(args) {
+			return nil, fmt.Errorf("%s: the arguments must be in parentheses", name)
+		}
+		fx := f.Fx
+		if stoppable, ok := stoppable[name]; ok {
+			fx = func(args []Value) (Value, error) { return stoppable(args, e.expired) }
+		}
+		x, err := fx(args)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return x, nil
+	}
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%s: wrong number of arguments", name)
+	}
+	// the angles in degrees like 45d are not converted from radians
+	if e.angleUnit == Radians && kinds[0] != Angle {
+		return e.applyRadians(name, args[0])
+	}
+	return e.applyFunc(name, args[0])
+}
+
+// applyFunc evaluates a function using the real implementation when
+// possible, out of the real domain the complex one is used in complex
+// mode. Vectors and matrices are evaluated element by element
+func (e *esolver) applyFunc(name string, x Value) (Value, error) {
+	if !isNumeric(x) {
+		return nil, fmt.Errorf("%s: %s argument not supported", name, typeName(x))
+	}
+	if isArray(x) {
+		return mapElements(x, func(v *big.Float) (Value, error) { return e.applyFunc(name, v) })
+	}
+	if v, ok := x.(*big.Float); ok {
+		inDomain, ok := realDomain[name]
+		if !ok || inDomain(v) {
+			return funcs[name](v), nil
+		}
+		if !e.complexMode {
+			return nil, fmt.Errorf("%s: %w", name, errDomain)
+		}
+	}
+
+	fx, ok := complexFuncs[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: complex argument not supported", name)
+	}
+	z, err := fx(toComplex(x))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return normalize(z), nil
+}
+
+func (e *esolver) applyOperator(name string, x, y Value) (Value, error) {
+	opr, ok := oprData[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown operator %q", name)
+	}
+	if fx, ok := logicData[name]; ok {
+		z, err := fx(x, y)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return z, nil
+	}
+	_, xDate := x.(*Date)
+	_, yDate := y.(*Date)
+	if xDate || yDate {
+		return dateOperator(name, x, y)
+	}
+	for _, v := range []Value{x, y} {
+		if !isNumeric(v) {
+			return nil, fmt.Errorf("%s: %s operand not supported", name, typeName(v))
+		}
+	}
+	if isArray(x) || isArray(y) {
+		return arrayOperator(name, x, y)
+	}
 
-			result := f(xVal, yVal)
-			stack.Push(Token{NUMBER, result.Text('f', -1)})
+	xf, xReal := x.(*big.Float)
+	yf, yReal := y.(*big.Float)
+	if xReal && yReal {
+		if z, ok := integerOperator(name, xf, yf); ok {
+			return z, nil
 		}
+		z, err := opr.fx(xf, yf)
+		if !errors.Is(err, errDomain) || !e.complexMode {
+			return z, err
+		}
+	}
+
+	if opr.cfx == nil {
+		return nil, fmt.Errorf("%s: complex operands not supported", name)
 	}
-	if len(stack.Values) != 1 {
-		return nil, errInvalidExpression
+	z, err := opr.cfx(toComplex(x), toComplex(y))
+	if err != nil {
+		return nil, err
 	}
-	result, _, err := big.ParseFloat(stack.Values[0].Value, 10, 256, big.ToNearestEven)
-	return result, err
+	return normalize(z), nil
 }
 
-func addMissingOperator(stack Stack) Stack {
+// addMissingOperator multiplies the adjacent operands like 2pi returning a
+// warning for each * inserted, in strict mode they are an error
+func addMissingOperator(stack Stack, strictness Strictness) (Stack, []string, error) {
 	if len(stack.Values) == 0 {
-		return stack
+		return stack, nil, nil
 	}
 
 	fixed := Stack{}
-	lastToken := TokenType(-1)
+	var warnings []string
+	last := Token{Type: -1}
 
 	for _, v := range stack.Values {
-		if (lastToken == NUMBER || lastToken == RPAREN || lastToken == CONSTANT) &&
-			(v.Type == NUMBER || v.Type == LPAREN || v.Type == CONSTANT || v.Type == FUNCTION) {
-			fixed.Push(Token{OPERATOR, "*"})
+		lastToken := last.Type
+		if (lastToken == NUMBER || lastToken == RPAREN || lastToken == RBRACKET || lastToken == CONSTANT || lastToken == VARIABLE) &&
+			(v.Type == NUMBER || v.Type == LPAREN || v.Type == LBRACKET || v.Type == CONSTANT || v.Type == VARIABLE || v.Type == FUNCTION) {
+			if strictness == Strict {
+				return Stack{}, nil, fmt.Errorf("missing operator between %s and %s", last.Value, v.Value)
+			}
+			warnings = append(warnings, fmt.Sprintf("* inserted between %s and %s", last.Value, v.Value))
+			fixed.Push(Token{Type: OPERATOR, Value: "*"})
 		}
 
-		lastToken = v.Type
+		last = v
 		fixed.Push(v)
 	}
-	return fixed
+	return fixed, warnings, nil
 }
 
 func (e *esolver) ParseExpression(s string) (Stack, error) {
+	stack, _, err := e.ParseWarnings(s)
+	return stack, err
+}
+
+// ParseWarnings parses the expression returning the warnings about the
+// operators inserted by the solver, like the * of 5 5
+func (e *esolver) ParseWarnings(s string) (Stack, []string, error) {
 	s = strings.ToLower(s)
 	s = strings.TrimSpace(s)
-	s = strings.Replace(s, ",", ".", -1)
 
 	p := NewParser(strings.NewReader(s), e.elemNames)
+	p.valueFuncs = e.valueFuncs
 
 	stack, err := p.Parse()
 	if err != nil {
-		return Stack{}, err
+		return Stack{}, nil, err
 	}
-	stack = addMissingOperator(stack)
-
-	return stack, nil
+	return addMissingOperator(stack, e.strictness)
 }
 
 func (e *esolver) AddConstant(name string, constCreator ConstFunction) {
-	e.userConsts[name] = constCreator
+	e.AddValue(name, func() Value { return decimalFloat(constCreator()) })
+}
+
+// AddValue defines a constant that may hold any kind of value
+func (e *esolver) AddValue(name string, valueCreator func() Value) {
+	e.userConsts[name] = valueCreator
+	delete(e.userKinds, name)
 	e.elemNames[name] = CONSTANT
 }
 
-func (e *esolver) findConst(name string) (c ConstFunction, ok bool) {
-	if c, ok = consts[name]; ok {
-		return c, ok
+// SetKind sets the kind of a constant added by AddValue, like an angle
+// assigned to a variable
+func (e *esolver) SetKind(name string, kind func() Kind) {
+	e.userKinds[name] = kind
+}
+
+// constKind returns the kind of a constant, the user constants keep the
+// kind set by SetKind
+func (e *esolver) constKind(name string, v Value) Kind {
+	if kind, ok := e.userKinds[name]; ok {
+		return kind()
+	}
+	return valueKind(v)
+}
+
+// AddFunction defines a function like the lookup of a table, the built-in
+// names can not be redefined
+func (e *esolver) AddFunction(name string, f ValueFunction) error {
+	if isBuiltin(name) {
+		return fmt.Errorf("%s is a built-in name", name)
+	}
+	delete(e.userConsts, name)
+	delete(e.userKinds, name)
+	e.valueFuncs[name] = f
+	e.elemNames[name] = FUNCTION
+	return nil
+}
+
+func isBuiltin(name string) bool {
+	_, isFunc := funcs[name]
+	_, isValueFunc := valueFuncs[name]
+	_, isConst := consts[name]
+	_, isComplex := complexConsts[name]
+	_, isBool := boolConsts[name]
+	_, isDate := dateConsts[name]
+	_, isLogic := logicData[name]
+	return isFunc || isValueFunc || isConst || isComplex || isBool || isDate || isLogic || name == "not"
+}
+
+// SetComplexMode enables complex results for real functions evaluated
+// out of their domain, like sqrt(-4)
+func (e *esolver) SetComplexMode(enabled bool) {
+	e.complexMode = enabled
+}
+
+func (e *esolver) ComplexMode() bool {
+	return e.complexMode
+}
+
+// SetStrictness sets which missing operators are inserted by
+// ParseExpression
+func (e *esolver) SetStrictness(s Strictness) {
+	e.strictness = s
+}
+
+func (e *esolver) Strictness() Strictness {
+	return e.strictness
+}
+
+// SetAngleUnit sets the unit of the angles of the trigonometric functions
+func (e *esolver) SetAngleUnit(u AngleUnit) {
+	e.angleUnit = u
+}
+
+func (e *esolver) AngleUnit() AngleUnit {
+	return e.angleUnit
+}
+
+// SetDeadline stops the evaluations after the time with ErrDeadline, the
+// zero time removes the deadline
+func (e *esolver) SetDeadline(t time.Time) {
+	e.deadline = t
+}
+
+// expired reports whether the deadline passed
+func (e *esolver) expired() bool {
+	return !e.deadline.IsZero() && time.Now().After(e.deadline)
+}
+
+// decimalFloat converts a constant to floatPrec bits through its shortest
+// decimal text, so the float64 25.4 of in is 25.4 and not
+// 25.39999999999999857891452847979962825775146484375
+func decimalFloat(x *big.Float) *big.Float {
+	z, _ := new(big.Float).SetPrec(floatPrec).SetString(x.Text('g', -1))
+	return z
+}
+
+func (e *esolver) findConst(name string) (Value, bool) {
+	if c, ok := consts[name]; ok {
+		return decimalFloat(c()), true
+	}
+	if c, ok := complexConsts[name]; ok {
+		return c(), true
+	}
+	if c, ok := boolConsts[name]; ok {
+		return c(), true
+	}
+	if c, ok := dateConsts[name]; ok {
+		return c(), true
+	}
+	if d, ok := parseDate(name); ok {
+		return d, true
+	}
+	if c, ok := e.userConsts[name]; ok {
+		return c(), true
 	}
-	c, ok = e.userConsts[name]
-	return
+	return nil, false
 }
@@ -1,9 +1,11 @@
 package esolver
 
 import (
+	"errors"
 	"math/big"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func Test_esolver_Solve(t *testing.T) {
@@ -67,37 +69,37 @@ func Test_esolver_ParseExpression(t *testing.T) {
 		want    Stack
 		wantErr bool
 	}{
-		{"literal", "1", Stack{[]Token{{NUMBER, "1"}}}, false},
-		{"literal more numbers", "100", Stack{[]Token{{NUMBER, "100"}}}, false},
-		{"addition", "1+2", Stack{[]Token{{NUMBER, "1"}, {OPERATOR, "+"}, {NUMBER, "2"}}}, false},
-		{"subtraction", "3-1", Stack{[]Token{{NUMBER, "3"}, {OPERATOR, "-"}, {NUMBER, "1"}}}, false},
-		{"multiplication", "2*3", Stack{[]Token{{NUMBER, "2"}, {OPERATOR, "*"}, {NUMBER, "3"}}}, false},
-		{"division", "4/2", Stack{[]Token{{NUMBER, "4"}, {OPERATOR, "/"}, {NUMBER, "2"}}}, false},
-		{"exponent", "2^3", Stack{[]Token{{NUMBER, "2"}, {OPERATOR, "^"}, {NUMBER, "3"}}}, false},
-		{"parentheses", "(1+2)", Stack{[]Token{{LPAREN, "("}, {NUMBER, "1"}, {OPERATOR, "+"}, {NUMBER, "2"}, {RPAREN, ")"}}}, false},
-		{"function", "sin(90)", Stack{[]Token{{FUNCTION, "sin"}, {LPAREN, "("}, {NUMBER, "90"}, {RPAREN, ")"}}}, false},
-		{"constant", "pi", Stack{[]Token{{CONSTANT, "pi"}}}, false},
-		{"unary minus", "-1", Stack{[]Token{{OPERATOR, "-"}, {NUMBER, "1"}}}, false},
-		{"unary plus", "+1", Stack{[]Token{{OPERATOR, "+"}, {NUMBER, "1"}}}, false},
+		{"literal", "1", Stack{[]Token{{Type: NUMBER, Value: "1"}}}, false},
+		{"literal more numbers", "100", Stack{[]Token{{Type: NUMBER, Value: "100"}}}, false},
+		{"addition", "1+2", Stack{[]Token{{Type: NUMBER, Value: "1"}, {Type: OPERATOR, Value: "+"}, {Type: NUMBER, Value: "2"}}}, false},
+		{"subtraction", "3-1", Stack{[]Token{{Type: NUMBER, Value: "3"}, {Type: OPERATOR, Value: "-"}, {Type: NUMBER, Value: "1"}}}, false},
+		{"multiplication", "2*3", Stack{[]Token{{Type: NUMBER, Value: "2"}, {Type: OPERATOR, Value: "*"}, {Type: NUMBER, Value: "3"}}}, false},
+		{"division", "4/2", Stack{[]Token{{Type: NUMBER, Value: "4"}, {Type: OPERATOR, Value: "/"}, {Type: NUMBER, Value: "2"}}}, false},
+		{"exponent", "2^3", Stack{[]Token{{Type: NUMBER, Value: "2"}, {Type: OPERATOR, Value: "^"}, {Type: NUMBER, Value: "3"}}}, false},
+		{"parentheses", "(1+2)", Stack{[]Token{{Type: LPAREN, Value: "("}, {Type: NUMBER, Value: "1"}, {Type: OPERATOR, Value: "+"}, {Type: NUMBER, Value: "2"}, {Type: RPAREN, Value: ")"}}}, false},
+		{"function", "sin(90)", Stack{[]Token{{Type: FUNCTION, Value: "sin"}, {Type: LPAREN, Value: "("}, {Type: NUMBER, Value: "90"}, {Type: RPAREN, Value: ")"}}}, false},
+		{"constant", "pi", Stack{[]Token{{Type: CONSTANT, Value: "pi"}}}, false},
+		{"unary minus", "-1", Stack{[]Token{{Type: OPERATOR, Value: "-"}, {Type: NUMBER, Value: "1"}}}, false},
+		{"unary plus", "+1", Stack{[]Token{{Type: OPERATOR, Value: "+"}, {Type: NUMBER, Value: "1"}}}, false},
 		{"complex expression", "5.5*pi+sin(90-10)", Stack{[]Token{
-			{NUMBER, "5.5"},
-			{OPERATOR, "*"},
-			{CONSTANT, "pi"},
-			{OPERATOR, "+"},
-			{FUNCTION, "sin"},
-			{LPAREN, "("},
-			{NUMBER, "90"},
-			{OPERATOR, "-"},
-			{NUMBER, "10"},
-			{RPAREN, ")"},
+			{Type: NUMBER, Value: "5.5"},
+			{Type: OPERATOR, Value: "*"},
+			{Type: CONSTANT, Value: "pi"},
+			{Type: OPERATOR, Value: "+"},
+			{Type: FUNCTION, Value: "sin"},
+			{Type: LPAREN, Value: "("},
+			{Type: NUMBER, Value: "90"},
+			{Type: OPERATOR, Value: "-"},
+			{Type: NUMBER, Value: "10"},
+			{Type: RPAREN, Value: ")"},
 		}}, false},
-		{"implicit multiplication constant", "3pi", Stack{[]Token{{NUMBER, "3"}, {OPERATOR, "*"}, {CONSTANT, "pi"}}}, false},
-		{"implicit multiplication function", "3sin(90)", Stack{[]Token{{NUMBER, "3"}, {OPERATOR, "*"}, {FUNCTION, "sin"}, {LPAREN, "("}, {NUMBER, "90"}, {RPAREN, ")"}}}, false},
-		{"implicit multiplication parentheses", "3(4)", Stack{[]Token{{NUMBER, "3"}, {OPERATOR, "*"}, {LPAREN, "("}, {NUMBER, "4"}, {RPAREN, ")"}}}, false},
-		{"implicit multiplication parentheses 2", "(3)4", Stack{[]Token{{LPAREN, "("}, {NUMBER, "3"}, {RPAREN, ")"}, {OPERATOR, "*"}, {NUMBER, "4"}}}, false},
-		{"implicit multiplication parentheses 3", "(3)(4)", Stack{[]Token{{LPAREN, "("}, {NUMBER, "3"}, {RPAREN, ")"}, {OPERATOR, "*"}, {LPAREN, "("}, {NUMBER, "4"}, {RPAREN, ")"}}}, false},
-		{"dms", `45d15'25"`, Stack{[]Token{{NUMBER, `45.2569444444444444444444444444444444444444444444444444444444444444444444444444`}}}, false},
-		{"tokenizer test", "1+*2", Stack{[]Token{{NUMBER, "1"}, {OPERATOR, "+"}, {OPERATOR, "*"}, {NUMBER, "2"}}}, false},
+		{"implicit multiplication constant", "3pi", Stack{[]Token{{Type: NUMBER, Value: "3"}, {Type: OPERATOR, Value: "*"}, {Type: CONSTANT, Value: "pi"}}}, false},
+		{"implicit multiplication function", "3sin(90)", Stack{[]Token{{Type: NUMBER, Value: "3"}, {Type: OPERATOR, Value: "*"}, {Type: FUNCTION, Value: "sin"}, {Type: LPAREN, Value: "("}, {Type: NUMBER, Value: "90"}, {Type: RPAREN, Value: ")"}}}, false},
+		{"implicit multiplication parentheses", "3(4)", Stack{[]Token{{Type: NUMBER, Value: "3"}, {Type: OPERATOR, Value: "*"}, {Type: LPAREN, Value: "("}, {Type: NUMBER, Value: "4"}, {Type: RPAREN, Value: ")"}}}, false},
+		{"implicit multiplication parentheses 2", "(3)4", Stack{[]Token{{Type: LPAREN, Value: "("}, {Type: NUMBER, Value: "3"}, {Type: RPAREN, Value: ")"}, {Type: OPERATOR, Value: "*"}, {Type: NUMBER, Value: "4"}}}, false},
+		{"implicit multiplication parentheses 3", "(3)(4)", Stack{[]Token{{Type: LPAREN, Value: "("}, {Type: NUMBER, Value: "3"}, {Type: RPAREN, Value: ")"}, {Type: OPERATOR, Value: "*"}, {Type: LPAREN, Value: "("}, {Type: NUMBER, Value: "4"}, {Type: RPAREN, Value: ")"}}}, false},
+		{"dms", `45d15'25"`, Stack{[]Token{{Type: NUMBER, Value: `45.2569444444444444444444444444444444444444444444444444444444444444444444444444`, Kind: Angle}}}, false},
+		{"tokenizer test", "1+*2", Stack{[]Token{{Type: NUMBER, Value: "1"}, {Type: OPERATOR, Value: "+"}, {Type: OPERATOR, Value: "*"}, {Type: NUMBER, Value: "2"}}}, false},
 	}
 	e := New()
 	for _, tt := range tests {
@@ -118,3 +120,99 @@ func Test_esolver_ParseExpression(t *testing.T) {
 		})
 	}
 }
+
+func Test_esolver_SolveFunctionGroup(t *testing.T) {
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"sqrt(4+5)", 3},
+		{"sqrt(4)+sqrt(9)", 5},
+		{"abs(2-5)*2", 6},
+		{"2*-3", -6},
+		{"-2^2", -4},
+		{"2^-1", 0.5},
+		{"sin-30", -0.5},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			x, err := e.Solve(tt.expr)
+			if err != nil {
+				t.Fatalf("Solve() failed: %v", err)
+			}
+			diff := new(big.Float).Sub(x, big.NewFloat(tt.want))
+			if diff.Abs(diff).Cmp(big.NewFloat(0.0000000001)) > 0 {
+				t.Errorf("Solve() = %v, want %v", x, tt.want)
+			}
+		})
+	}
+}
+
+func Test_esolver_FunctionBinding(t *testing.T) {
+	tests := []struct {
+		expr string
+		same string
+	}{
+		// without parentheses a function takes the next operand only
+		{"sin30^2", "(sin(30))^2"},
+		{"sin30+cos60", "(sin(30))+(cos(60))"},
+		{"sqrt4+sqrt9", "sqrt(4)+sqrt(9)"},
+		{"sqrt4*3", "(sqrt(4))*3"},
+		{"2^sqrt4", "2^(sqrt(4))"},
+		{"ln e^2", "(ln(e))^2"},
+		{"sin cos 0", "sin(cos(0))"},
+		// a prefix operator extends the argument to its own operand
+		{"sin-30^2", "sin(-(30^2))"},
+		{"sin-30*2", "(sin(-30))*2"},
+		// with parentheses the function takes the whole group
+		{"sqrt(4+5)", "sqrt(9)"},
+		{"sin(30)^2", "(sin(30))^2"},
+		{"2sqrt(16)/4", "(2*(sqrt(16)))/4"},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := e.Solve(tt.expr)
+			if err != nil {
+				t.Fatalf("Solve() failed: %v", err)
+			}
+			want, err := e.Solve(tt.same)
+			if err != nil {
+				t.Fatalf("Solve(%q) failed: %v", tt.same, err)
+			}
+			if got.Cmp(want) != 0 {
+				t.Errorf("Solve() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_esolver_SetDeadline(t *testing.T) {
+	e := New()
+	e.SetDeadline(time.Now())
+	if _, err := e.Eval("1 + 2"); !errors.Is(err, ErrDeadline) {
+		t.Errorf("Eval() error = %v, want %v", err, ErrDeadline)
+	}
+	e.SetDeadline(time.Time{})
+	if got, err := e.Solve("1 + 2"); err != nil || got.Cmp(big.NewFloat(3)) != 0 {
+		t.Errorf("Solve() without deadline = %v, %v, want 3", got, err)
+	}
+
+	// the loops of the stoppable functions stop too
+	stop := func() bool { return true }
+	// 2^127 - 3 has two large prime factors found by Pollard's rho
+	n, _ := parseNumber("170141183460469231731687303715884105725")
+	args := map[string][]Value{
+		"factor":     {n},
+		"binompdf":   {big.NewFloat(1000), big.NewFloat(1000), big.NewFloat(0.5)},
+		"binomcdf":   {big.NewFloat(1000), big.NewFloat(1000), big.NewFloat(0.5)},
+		"poissonpdf": {big.NewFloat(1000), big.NewFloat(5)},
+		"poissoncdf": {big.NewFloat(1000), big.NewFloat(5)},
+	}
+	for name, f := range stoppable {
+		if _, err := f(args[name], stop); !errors.Is(err, ErrDeadline) {
+			t.Errorf("%s error = %v, want %v", name, err, ErrDeadline)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package esolver
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Span is a token of an expression with the positions in runes of its first
+// rune and of the rune after it
+type Span struct {
+	Token
+	Start, End int
+}
+
+// Spans scans the expression returning its tokens without the whitespace for
+// the highlighting of the input. The unknown names are ERROR, unless they are
+// assigned or they are in the arguments of a lazy function like solve
+func (e *esolver) Spans(s string) []Span {
+	// ToLower may change the length of the text, the positions are kept
+	s = strings.Map(unicode.ToLower, s)
+	scanner := NewScanner(strings.NewReader(s), e.elemNames)
+
+	var spans []Span
+	// the parentheses and brackets opened, a comma separates their items
+	// like in Parse and the arguments of lazy functions may have any name
+	type group struct{ separates, lazy bool }
+	var groups []group
+	last := Token{Type: EOF}
+	for {
+		scanner.DecimalComma = len(groups) == 0 || !groups[len(groups)-1].separates
+		start := scanner.Offset()
+		t := scanner.Scan()
+		if t.Type == EOF {
+			break
+		}
+		span := Span{Token: t, Start: start, End: scanner.Offset()}
+		switch t.Type {
+		case WHITESPACE:
+			continue
+		case LPAREN:
+			_, multiArg := e.valueFuncs[last.Value]
+			groups = append(groups, group{
+				separates: last.Type == FUNCTION && multiArg,
+				lazy:      last.Type == FUNCTION && e.valueFuncs[last.Value].Lazy,
+			})
+		case LBRACKET:
+			groups = append(groups, group{separates: true})
+		case RPAREN, RBRACKET:
+			if len(groups) > 0 {
+				groups = groups[:len(groups)-1]
+			}
+		case OPERATOR:
+			if n := len(spans); t.Value == "=" && n > 0 && last.Type == VARIABLE {
+				// the name of an assignment
+				spans[n-1].Type = VARIABLE
+			}
+		case VARIABLE:
+			if !slices.ContainsFunc(groups, func(g group) bool { return g.lazy }) {
+				span.Type = ERROR
+			}
+		}
+		last = t
+		spans = append(spans, span)
+	}
+	return spans
+}
@@ -0,0 +1,51 @@
+package esolver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_esolver_Spans(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []Span
+	}{
+		{"2*Sqrt(pi)", []Span{
+			{Token{Type: NUMBER, Value: "2"}, 0, 1},
+			{Token{Type: OPERATOR, Value: "*"}, 1, 2},
+			{Token{Type: FUNCTION, Value: "sqrt"}, 2, 6},
+			{Token{Type: LPAREN, Value: "("}, 6, 7},
+			{Token{Type: CONSTANT, Value: "pi"}, 7, 9},
+			{Token{Type: RPAREN, Value: ")"}, 9, 10},
+		}},
+		{"45°30′ + y", []Span{
+			{Token{Type: NUMBER, Value: "45.5", Kind: Angle}, 0, 6},
+			{Token{Type: OPERATOR, Value: "+"}, 7, 8},
+			{Token{Type: ERROR, Value: "y"}, 9, 10},
+		}},
+		{"x = 2", []Span{
+			{Token{Type: VARIABLE, Value: "x"}, 0, 1},
+			{Token{Type: OPERATOR, Value: "="}, 2, 3},
+			{Token{Type: NUMBER, Value: "2"}, 4, 5},
+		}},
+		{"diff(x^2, x) $", []Span{
+			{Token{Type: FUNCTION, Value: "diff"}, 0, 4},
+			{Token{Type: LPAREN, Value: "("}, 4, 5},
+			{Token{Type: VARIABLE, Value: "x"}, 5, 6},
+			{Token{Type: OPERATOR, Value: "^"}, 6, 7},
+			{Token{Type: NUMBER, Value: "2"}, 7, 8},
+			{Token{Type: SEPARATOR, Value: ","}, 8, 9},
+			{Token{Type: VARIABLE, Value: "x"}, 10, 11},
+			{Token{Type: RPAREN, Value: ")"}, 11, 12},
+			{Token{Type: ERROR, Value: "$"}, 13, 14},
+		}},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if got := e.Spans(tt.expr); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Spans() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
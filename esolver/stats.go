@@ -0,0 +1,272 @@
+package esolver
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+var errTwoValues = errors.New("at least two values are needed")
+
+// samples flattens the arguments of the statistics functions, numbers,
+// vectors and matrices, to a list of numbers
+func samples(args []Value) ([]*big.Float, error) {
+	var xs []*big.Float
+	for _, arg := range args {
+		switch x := arg.(type) {
+		case Vector:
+			xs = append(xs, x...)
+		case Matrix:
+			for _, row := range x {
+				xs = append(xs, row...)
+			}
+		default:
+			v, err := Real(x)
+			if err != nil {
+				return nil, fmt.Errorf("%s argument not supported", typeName(x))
+			}
+			xs = append(xs, v)
+		}
+	}
+	return xs, nil
+}
+
+func sortedSamples(args []Value) ([]*big.Float, error) {
+	xs, err := samples(args)
+	if err != nil {
+		return nil, err
+	}
+	xs = slices.Clone(xs)
+	slices.SortFunc(xs, func(a, b *big.Float) int { return a.Cmp(b) })
+	return xs, nil
+}
+
+func total(xs []*big.Float) *big.Float {
+	sum := new(big.Float).SetPrec(floatPrec)
+	for _, x := range xs {
+		sum.Add(sum, x)
+	}
+	return sum
+}
+
+func average(xs []*big.Float) *big.Float {
+	sum := total(xs)
+	return sum.Quo(sum, new(big.Float).SetInt64(int64(len(xs))))
+}
+
+// sumSquares returns the sum of the squared deviations from the mean
+func sumSquares(xs []*big.Float) *big.Float {
+	mean := average(xs)
+	sum := new(big.Float).SetPrec(floatPrec)
+	for _, x := range xs {
+		d := new(big.Float).Sub(x, mean)
+		sum.Add(sum, d.Mul(d, d))
+	}
+	return sum
+}
+
+func sumFunc(args []Value) (Value, error) {
+	xs, err := samples(args)
+	if err != nil {
+		return nil, err
+	}
+	return total(xs), nil
+}
+
+func meanFunc(args []Value) (Value, error) {
+	xs, err := samples(args)
+	if err != nil {
+		return nil, err
+	}
+	return average(xs), nil
+}
+
+func medianFunc(args []Value) (Value, error) {
+	xs, err := sortedSamples(args)
+	if err != nil {
+		return nil, err
+	}
+	n := len(xs)
+	if n%2 == 1 {
+		return xs[n/2], nil
+	}
+	return average(xs[n/2-1 : n/2+1]), nil
+}
+
+// modeFunc returns the most frequent value, the smallest one on a tie
+func modeFunc(args []Value) (Value, error) {
+	xs, err := sortedSamples(args)
+	if err != nil {
+		return nil, err
+	}
+	mode, best := xs[0], 0
+	for i := 0; i < len(xs); {
+		j := i + 1
+		for j < len(xs) && xs[j].Cmp(xs[i]) == 0 {
+			j++
+		}
+		if j-i > best {
+			mode, best = xs[i], j-i
+		}
+		i = j
+	}
+	return mode, nil
+}
+
+// varFunc returns the sample variance
+func varFunc(args []Value) (Value, error) {
+	xs, err := samples(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(xs) < 2 {
+		return nil, errTwoValues
+	}
+	sum := sumSquares(xs)
+	return sum.Quo(sum, new(big.Float).SetInt64(int64(len(xs)-1))), nil
+}
+
+// stdevFunc returns the sample standard deviation
+func stdevFunc(args []Value) (Value, error) {
+	v, err := varFunc(args)
+	if err != nil {
+		return nil, err
+	}
+	x := v.(*big.Float)
+	return x.Sqrt(x), nil
+}
+
+func minFunc(args []Value) (Value, error) {
+	xs, err := samples(args)
+	if err != nil {
+		return nil, err
+	}
+	return slices.MinFunc(xs, func(a, b *big.Float) int { return a.Cmp(b) }), nil
+}
+
+func maxFunc(args []Value) (Value, error) {
+	xs, err := samples(args)
+	if err != nil {
+		return nil, err
+	}
+	return slices.MaxFunc(xs, func(a, b *big.Float) int { return a.Cmp(b) }), nil
+}
+
+// percentile returns percentile(data, p) interpolating linearly between
+// the sorted values, p = 50 is the median
+func percentile(args []Value) (Value, error) {
+	xs, err := sortedSamples(args[:1])
+	if err != nil {
+		return nil, err
+	}
+	p, err := Real(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if p.Sign() < 0 || p.Cmp(big.NewFloat(100)) > 0 {
+		return nil, errors.New("the percentile must be between 0 and 100")
+	}
+	rank := new(big.Float).SetPrec(floatPrec).Mul(p, new(big.Float).SetInt64(int64(len(xs)-1)))
+	rank.Quo(rank, big.NewFloat(100))
+	i, _ := rank.Int64()
+	if int(i) == len(xs)-1 {
+		return xs[i], nil
+	}
+	frac := rank.Sub(rank, new(big.Float).SetInt64(i))
+	return lerp(xs[i], xs[i+1], frac), nil
+}
+
+// linreg fits the line y = slope*x + intercept by least squares and
+// returns [slope, intercept, r²]. The points are given by the vectors x
+// and y, by a matrix with the columns x and y or by the vector y alone
+// with x = 1, 2, ...
+func linreg(args []Value) (Value, error) {
+	var xs, ys []*big.Float
+	switch {
+	case len(args) == 2:
+		x, xOk := args[0].(Vector)
+		y, yOk := args[1].(Vector)
+		if !xOk || !yOk {
+			return nil, errors.New("x and y must be vectors")
+		}
+		if len(x) != len(y) {
+			return nil, errDimension
+		}
+		xs, ys = x, y
+	case isArray(args[0]):
+		if m, ok := args[0].(Matrix); ok {
+			if len(m[0]) != 2 {
+				return nil, errors.New("the matrix must have the columns x and y")
+			}
+			for _, row := range m {
+				xs, ys = append(xs, row[0]), append(ys, row[1])
+			}
+			break
+		}
+		ys = args[0].(Vector)
+		for i := range ys {
+			xs = append(xs, new(big.Float).SetInt64(int64(i+1)))
+		}
+	default:
+		return nil, errNotArray
+	}
+	if len(xs) < 2 {
+		return nil, errTwoValues
+	}
+
+	mx, my := average(xs), average(ys)
+	sxx, sxy := new(big.Float).SetPrec(floatPrec), new(big.Float).SetPrec(floatPrec)
+	for i := range xs {
+		dx := new(big.Float).Sub(xs[i], mx)
+		dy := new(big.Float).Sub(ys[i], my)
+		sxy.Add(sxy, new(big.Float).Mul(dx, dy))
+		sxx.Add(sxx, dx.Mul(dx, dx))
+	}
+	if sxx.Sign() == 0 {
+		return nil, errors.New("the x values must not be all equal")
+	}
+	syy := sumSquares(ys)
+
+	slope := new(big.Float).Quo(sxy, sxx)
+	intercept := new(big.Float).Sub(my, new(big.Float).Mul(slope, mx))
+	r2 := big.NewFloat(1)
+	if syy.Sign() != 0 {
+		r2 = new(big.Float).Mul(sxy, sxy)
+		r2.Quo(r2, new(big.Float).Mul(sxx, syy))
+	}
+	return Vector{slope, intercept, r2}, nil
+}
+
+// ReadNumbers reads the numbers separated by spaces, commas, semicolons or
+// new lines, the lines starting with # are comments
+func ReadNumbers(r io.Reader) (Vector, error) {
+	var v Vector
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.FieldsFunc(text, func(r rune) bool {
+			return r == ',' || r == ';' || r == ' ' || r == '\t'
+		})
+		for _, field := range fields {
+			x, _, err := big.ParseFloat(field, 10, floatPrec, big.ToNearestEven)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %q is not a number", line, field)
+			}
+			v = append(v, x)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(v) == 0 {
+		return nil, errors.New("no numbers found")
+	}
+	return v, nil
+}
@@ -0,0 +1,95 @@
+package esolver
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func Test_esolver_Statistics(t *testing.T) {
+	tests := []struct {
+		expr string
+		want Value
+	}{
+		{"{1, 2, 3}", vector(1, 2, 3)},
+		{"{{1, 2}, {3, 4}}", matrix(2, 1, 2, 3, 4)},
+		{"sum{1, 2, 3}", big.NewFloat(6)},
+		{"sum([1, 2], 3)", big.NewFloat(6)},
+		{"sum([[1, 2], [3, 4]])", big.NewFloat(10)},
+		{"mean(1, 2, 3, 4)", big.NewFloat(2.5)},
+		{"mean{1, 2, 3, 4}", big.NewFloat(2.5)},
+		{"median{3, 1, 2}", big.NewFloat(2)},
+		{"median{4, 1, 2, 3}", big.NewFloat(2.5)},
+		{"mode{3, 1, 2, 2, 3}", big.NewFloat(2)},
+		{"mode{5}", big.NewFloat(5)},
+		{"var{2, 4, 4, 4, 5, 5, 7, 9}", big.NewFloat(32.0 / 7)},
+		{"stdev{2, 4, 4, 4, 5, 5, 7, 9}", big.NewFloat(2.1380899352993950)},
+		{"min{3, -1, 2}", big.NewFloat(-1)},
+		{"max(3, -1, 2)", big.NewFloat(3)},
+		{"min 3 + 1", big.NewFloat(4)},
+		{"percentile({1, 2, 3, 4, 5}, 25)", big.NewFloat(2)},
+		{"percentile({4, 1, 3, 2}, 50)", big.NewFloat(2.5)},
+		{"percentile({1, 2, 3, 4}, 90)", big.NewFloat(3.7)},
+		{"percentile({1, 2, 3, 4}, 0)", big.NewFloat(1)},
+		{"percentile({1, 2, 3, 4}, 100)", big.NewFloat(4)},
+		{"linreg({1, 2, 3}, {2, 4, 6})", vector(2, 0, 1)},
+		{"linreg({1, 2, 3}, {2, 4, 6.5})", vector(2.25, -1.0/3, 0.9959016393442623)},
+		{"linreg{1, 3, 5}", vector(2, -1, 1)},
+		{"linreg{{0, 1}, {1, 3}, {2, 5}}", vector(2, 1, 1)},
+		{"linreg({1, 2, 3}, {4, 4, 4})", vector(0, 4, 1)},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := e.Eval(tt.expr)
+			if err != nil {
+				t.Fatalf("Eval() failed: %v", err)
+			}
+			if !sameValue(got, tt.want) {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_esolver_StatisticsErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"var{1}", "var: at least two values are needed"},
+		{"stdev(5)", "stdev: at least two values are needed"},
+		{"mean{1, i}", "vector and matrix elements must be real"},
+		{"percentile({1, 2}, 101)", "percentile: the percentile must be between 0 and 100"},
+		{"linreg({1, 2}, {1, 2, 3})", "linreg: dimension mismatch"},
+		{"linreg({1, 1}, {1, 2})", "linreg: the x values must not be all equal"},
+		{"linreg{{1, 2, 3}, {4, 5, 6}}", "linreg: the matrix must have the columns x and y"},
+		{"linreg(2)", "linreg: argument must be a vector or matrix"},
+		{"sum(true)", "sum: boolean argument not supported"},
+		{"mean(1, 2i)", "mean: complex argument not supported"},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := e.Eval(tt.expr)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Eval() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadNumbers(t *testing.T) {
+	got, err := ReadNumbers(strings.NewReader("# loads in kN\n1.5 2, 3\n\n4;5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := vector(1.5, 2, 3, 4, 5); !sameValue(got, want) {
+		t.Errorf("ReadNumbers() = %v, want %v", got, want)
+	}
+	for _, input := range []string{"", "# nothing\n", "1 2\nthree\n"} {
+		if v, err := ReadNumbers(strings.NewReader(input)); err == nil {
+			t.Errorf("ReadNumbers(%q) = %v, expected error", input, v)
+		}
+	}
+}
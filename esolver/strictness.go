@@ -0,0 +1,37 @@
+package esolver
+
+import "fmt"
+
+// Strictness sets which missing operators the solver inserts and when ans
+// is used for a missing operand
+type Strictness int
+
+const (
+	// Legacy multiplies adjacent operands like 5 5 and uses ans for a
+	// missing operand like *2 or sin
+	Legacy Strictness = iota
+	// ImplicitMultiplication multiplies adjacent operands like 2pi but
+	// never uses ans
+	ImplicitMultiplication
+	// Strict requires every operator and operand
+	Strict
+)
+
+var strictnessNames = []string{"legacy", "implicit", "strict"}
+
+func (s Strictness) String() string {
+	if s < 0 || int(s) >= len(strictnessNames) {
+		return fmt.Sprintf("Strictness(%d)", int(s))
+	}
+	return strictnessNames[s]
+}
+
+// ParseStrictness returns the strictness named legacy, implicit or strict
+func ParseStrictness(name string) (Strictness, error) {
+	for i, s := range strictnessNames {
+		if s == name {
+			return Strictness(i), nil
+		}
+	}
+	return Legacy, fmt.Errorf("unknown strictness %q, use legacy, implicit or strict", name)
+}
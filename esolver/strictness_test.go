@@ -0,0 +1,52 @@
+package esolver
+
+import (
+	"slices"
+	"testing"
+)
+
+func Test_esolver_ParseWarnings(t *testing.T) {
+	tests := []struct {
+		expr       string
+		strictness Strictness
+		want       []string
+		wantErr    string
+	}{
+		{"5 5", Legacy, []string{"* inserted between 5 and 5"}, ""},
+		{"2pi(1+2)", Legacy, []string{"* inserted between 2 and pi", "* inserted between pi and ("}, ""},
+		{"5tan45", ImplicitMultiplication, []string{"* inserted between 5 and tan"}, ""},
+		{"2*pi", Strict, nil, ""},
+		{"sin30^2", Strict, nil, ""},
+		{"5 5", Strict, nil, "missing operator between 5 and 5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strictness.String()+" "+tt.expr, func(t *testing.T) {
+			e := New()
+			e.SetStrictness(tt.strictness)
+			_, got, err := e.ParseWarnings(tt.expr)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseWarnings() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWarnings() failed: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseWarnings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStrictness(t *testing.T) {
+	for _, s := range []Strictness{Legacy, ImplicitMultiplication, Strict} {
+		if got, err := ParseStrictness(s.String()); err != nil || got != s {
+			t.Errorf("ParseStrictness(%q) = %v, %v", s, got, err)
+		}
+	}
+	if _, err := ParseStrictness("loose"); err == nil {
+		t.Error("ParseStrictness(loose) did not fail")
+	}
+}
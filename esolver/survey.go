@@ -0,0 +1,167 @@
+package esolver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+// Bearing is a direction given by its azimuth in degrees clockwise from
+// the north, printed as a quadrant bearing like N45d30'E. The inverse
+// between two points keeps the Distance too. It is used as the azimuth in
+// the following operations
+type Bearing struct {
+	Azimuth  *big.Float
+	Distance *big.Float
+}
+
+// Quadrant returns the quadrant bearing, the angle from the north or the
+// south to the east or the west, like N, 45, E
+func (b *Bearing) Quadrant() (from string, angle *big.Float, to string) {
+	az := b.Azimuth
+	switch {
+	case az.Cmp(big.NewFloat(90)) <= 0:
+		return "N", az, "E"
+	case az.Cmp(big.NewFloat(180)) <= 0:
+		return "S", new(big.Float).Sub(big.NewFloat(180), az), "E"
+	case az.Cmp(big.NewFloat(270)) < 0:
+		return "S", new(big.Float).Sub(az, big.NewFloat(180)), "W"
+	}
+	return "N", new(big.Float).Sub(big.NewFloat(360), az), "W"
+}
+
+// normalizeAngle returns the angle between 0 and 360 degrees
+func normalizeAngle(x *big.Float) *big.Float {
+	turns := new(big.Float).Quo(x, big.NewFloat(360))
+	n, _ := turns.Int(nil)
+	if turns.Sign() < 0 && !turns.IsInt() {
+		n.Sub(n, big.NewInt(1))
+	}
+	z := new(big.Float).SetPrec(max(x.Prec(), floatPrec)).SetInt(n)
+	z.Mul(z, big.NewFloat(360))
+	return z.Sub(x, z)
+}
+
+// bearingToAzimuth converts the angle of a quadrant bearing to an azimuth
+func bearingToAzimuth(from rune, angle *big.Float, to rune) (*big.Float, error) {
+	if angle.Sign() < 0 || angle.Cmp(big.NewFloat(90)) > 0 {
+		return nil, errors.New("the angle of a bearing must be between 0 and 90 degrees")
+	}
+	az := new(big.Float).SetPrec(floatPrec)
+	switch {
+	case from == 'n' && to == 'e':
+		az.Set(angle)
+	case from == 's' && to == 'e':
+		az.Sub(big.NewFloat(180), angle)
+	case from == 's' && to == 'w':
+		az.Add(big.NewFloat(180), angle)
+	default:
+		az.Sub(big.NewFloat(360), angle)
+		az = normalizeAngle(az)
+	}
+	return az, nil
+}
+
+// scanBearing returns the azimuth of a quadrant bearing like n45d30'e whose
+// first letter word was read
+func (s *Scanner) scanBearing(word string) (Token, bool) {
+	if word != "n" && word != "s" {
+		return Token{}, false
+	}
+	const maxBearingLen = 32
+	runes := s.Peek(maxBearingLen)
+	i := 0
+	for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || isDegree(runes[i])) {
+		i++
+	}
+	if i == 0 || !unicode.IsDigit(runes[0]) || i == len(runes) || (runes[i] != 'e' && runes[i] != 'w') ||
+		i+1 < len(runes) && (unicode.IsLetter(runes[i+1]) || unicode.IsDigit(runes[i+1])) {
+		return Token{}, false
+	}
+	for range i + 1 {
+		s.Read()
+	}
+	text := word + string(runes[:i+1])
+	angle := []rune(string(runes[:i]))
+	for j, r := range angle {
+		angle[j] = asciiMarker(r)
+	}
+	az, err := bearingToAzimuth(rune(word[0]), degToDec(string(angle)), runes[i])
+	if err != nil {
+		return Token{Type: ERROR, Value: strings.ToUpper(text)}, true
+	}
+	return Token{Type: NUMBER, Value: az.Text('f', -1), Kind: Angle}, true
+}
+
+// bearingFunc returns the bearing of an azimuth
+func bearingFunc(args []Value) (Value, error) {
+	az, err := Real(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("%s argument not supported", typeName(args[0]))
+	}
+	return &Bearing{Azimuth: normalizeAngle(az)}, nil
+}
+
+// azimuth returns the angle between 0 and 360 degrees, so azimuth(az + 180)
+// is the back azimuth
+func azimuth(args []Value) (Value, error) {
+	az, err := Real(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("%s argument not supported", typeName(args[0]))
+	}
+	return normalizeAngle(az), nil
+}
+
+// latdep returns the latitude and the departure [Δnorth, Δeast] of a
+// traverse line latdep(azimuth, distance)
+func latdep(args []Value) (Value, error) {
+	x, err := reals(args, 2)
+	if err != nil {
+		return nil, err
+	}
+	az, _ := x[0].Float64()
+	sin, cos := sincosDeg(az)
+	lat := new(big.Float).SetPrec(floatPrec).Mul(x[1], big.NewFloat(cos))
+	dep := new(big.Float).SetPrec(floatPrec).Mul(x[1], big.NewFloat(sin))
+	return Vector{lat, dep}, nil
+}
+
+// inverse returns the distance and the bearing from the first point to the
+// second, given as inverse(n1, e1, n2, e2) or inverse([n1, e1], [n2, e2])
+func inverse(args []Value) (Value, error) {
+	var coords []Value
+	switch len(args) {
+	case 2:
+		for _, arg := range args {
+			v, ok := arg.(Vector)
+			if !ok || len(v) != 2 {
+				return nil, errors.New("the points must be vectors [north, east]")
+			}
+			coords = append(coords, v[0], v[1])
+		}
+	case 4:
+		coords = args
+	default:
+		return nil, errors.New("give two points [north, east] or their four coordinates")
+	}
+	x, err := reals(coords, 4)
+	if err != nil {
+		return nil, err
+	}
+	dn := new(big.Float).SetPrec(floatPrec).Sub(x[2], x[0])
+	de := new(big.Float).SetPrec(floatPrec).Sub(x[3], x[1])
+	if dn.Sign() == 0 && de.Sign() == 0 {
+		return nil, errors.New("the points must be different")
+	}
+	distance := new(big.Float).Mul(dn, dn)
+	distance.Add(distance, new(big.Float).Mul(de, de))
+	distance.Sqrt(distance)
+
+	n, _ := dn.Float64()
+	e, _ := de.Float64()
+	az := big.NewFloat(math.Atan2(e, n) * radToDeg)
+	return &Bearing{Azimuth: normalizeAngle(az), Distance: distance}, nil
+}
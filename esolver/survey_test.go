@@ -0,0 +1,87 @@
+package esolver
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_esolver_Survey(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []float64
+	}{
+		{"N45d30'E", []float64{45.5}},
+		{"S45d30'E", []float64{134.5}},
+		{"S30W", []float64{210}},
+		{"N30W", []float64{330}},
+		{"N0W", []float64{0}},
+		{"s89d59'60\"w", []float64{270}},
+		{"azimuth(-30)", []float64{330}},
+		{"azimuth(N30W + 180)", []float64{150}},
+		{"azimuth(720.5)", []float64{0.5}},
+		{"bearing(135.5)", []float64{135.5}},
+		{"2*bearing(45)", []float64{90}},
+		{"latdep(45, 100)", []float64{70.710678118654752, 70.710678118654752}},
+		{"latdep(S30E, 10)", []float64{-8.6602540378443865, 5}},
+		{"latdep(270, 10)", []float64{0, -10}},
+		{"inverse(0, 0, 100, 100)", []float64{45, 141.42135623730950}},
+		{"inverse([100, 100], [0, 200])", []float64{135, 141.42135623730950}},
+		{"inverse(0, 0, -3, -4)", []float64{233.13010235415598, 5}},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			v, err := e.Eval(tt.expr)
+			if err != nil {
+				t.Fatalf("Eval() failed: %v", err)
+			}
+			var got []*big.Float
+			switch x := v.(type) {
+			case Vector:
+				got = x
+			case *Bearing:
+				got = []*big.Float{x.Azimuth}
+				if x.Distance != nil {
+					got = append(got, x.Distance)
+				}
+			default:
+				r, err := Real(x)
+				if err != nil {
+					t.Fatalf("Eval() = %v, want a number", x)
+				}
+				got = []*big.Float{r}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Eval() = %v, want %v", got, tt.want)
+			}
+			for i, want := range tt.want {
+				diff := new(big.Float).Sub(got[i], big.NewFloat(want))
+				if diff.Abs(diff).Cmp(big.NewFloat(1e-12)) > 0 {
+					t.Errorf("Eval() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func Test_esolver_SurveyErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"N95E", `ERROR: "N95E"`},
+		{"inverse(1, 2, 1, 2)", "inverse: the points must be different"},
+		{"inverse([1, 2], 3)", "inverse: the points must be vectors [north, east]"},
+		{"inverse(1, 2, 3)", "inverse: give two points [north, east] or their four coordinates"},
+		{"latdep(2i, 10)", "latdep: complex argument not supported"},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := e.Eval(tt.expr)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Eval() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
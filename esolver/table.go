@@ -0,0 +1,237 @@
+package esolver
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Interpolation selects how a table computes the values between its keys
+type Interpolation int
+
+const (
+	Linear  Interpolation = iota // straight line between the neighbouring keys
+	Nearest                      // value of the nearest key
+	Step                         // value of the greatest key not above
+)
+
+var interpolationNames = []string{"linear", "nearest", "step"}
+
+func (m Interpolation) String() string {
+	return interpolationNames[m]
+}
+
+// ParseInterpolation returns the interpolation named linear, nearest or step
+func ParseInterpolation(s string) (Interpolation, error) {
+	if i := slices.Index(interpolationNames, strings.ToLower(s)); i >= 0 {
+		return Interpolation(i), nil
+	}
+	return Linear, fmt.Errorf("unknown interpolation %q, use linear, nearest or step", s)
+}
+
+// Table is a lookup table read from CSV. The first column holds the keys
+// of the rows in increasing order and the first row the names of the
+// columns. When the first row holds numbers the table is a grid looked up
+// by the keys of the row and of the column
+type Table struct {
+	Columns    []string     // names of the columns, empty for a grid
+	Keys       []*big.Float // keys of the rows
+	ColumnKeys []*big.Float // keys of the columns of a grid
+	Values     [][]*big.Float
+	Mode       Interpolation
+}
+
+// ReadTable reads a table in CSV format, the lines starting with # are
+// comments
+func ReadTable(r io.Reader, mode Interpolation) (*Table, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.TrimLeadingSpace = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 || len(records[0]) < 2 {
+		return nil, errors.New("a table needs a header, a key column and one row at least")
+	}
+
+	t := &Table{Mode: mode}
+	header := records[0][1:]
+	if keys, err := parseNumbers(header); err == nil {
+		if !increasing(keys) {
+			return nil, errors.New("the keys of the columns must be increasing")
+		}
+		t.ColumnKeys = keys
+	} else {
+		for _, name := range header {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if !isWord(name) {
+				// the scanner reads fy2 as fy*2, the column could not be looked up
+				return nil, fmt.Errorf("the column name %q must be letters only", name)
+			}
+			t.Columns = append(t.Columns, name)
+		}
+	}
+
+	for i, record := range records[1:] {
+		row, err := parseNumbers(record)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		t.Keys = append(t.Keys, row[0])
+		t.Values = append(t.Values, row[1:])
+	}
+	if !increasing(t.Keys) {
+		return nil, errors.New("the keys of the rows must be increasing")
+	}
+	return t, nil
+}
+
+// isWord reports whether s is a word as the scanner reads it, letters only
+func isWord(s string) bool {
+	for _, ch := range s {
+		if !unicode.IsLetter(ch) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func parseNumbers(cells []string) ([]*big.Float, error) {
+	numbers := make([]*big.Float, len(cells))
+	for i, cell := range cells {
+		x, _, err := big.ParseFloat(strings.TrimSpace(cell), 10, floatPrec, big.ToNearestEven)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", cell)
+		}
+		numbers[i] = x
+	}
+	return numbers, nil
+}
+
+func increasing(keys []*big.Float) bool {
+	for i := 1; i < len(keys); i++ {
+		if keys[i].Cmp(keys[i-1]) <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsGrid reports whether the table is looked up by row and column keys
+func (t *Table) IsGrid() bool {
+	return t.ColumnKeys != nil
+}
+
+// Lookup returns the value of the column at the key x
+func (t *Table) Lookup(column int, x *big.Float) (*big.Float, error) {
+	i, j, frac, err := t.locate(t.Keys, x)
+	if err != nil {
+		return nil, err
+	}
+	return lerp(t.Values[i][column], t.Values[j][column], frac), nil
+}
+
+// LookupGrid returns the value of a grid at the row key x and the column
+// key y, the linear mode interpolates bilinearly
+func (t *Table) LookupGrid(x, y *big.Float) (*big.Float, error) {
+	i, j, rowFrac, err := t.locate(t.Keys, x)
+	if err != nil {
+		return nil, err
+	}
+	k, l, colFrac, err := t.locate(t.ColumnKeys, y)
+	if err != nil {
+		return nil, err
+	}
+	a := lerp(t.Values[i][k], t.Values[i][l], colFrac)
+	b := lerp(t.Values[j][k], t.Values[j][l], colFrac)
+	return lerp(a, b, rowFrac), nil
+}
+
+// locate returns the positions i and j of the keys around x and the
+// fraction of the way from the key i to the key j given by the mode
+func (t *Table) locate(keys []*big.Float, x *big.Float) (i, j int, frac *big.Float, err error) {
+	n := len(keys)
+	if x.Cmp(keys[0]) < 0 || x.Cmp(keys[n-1]) > 0 {
+		return 0, 0, nil, fmt.Errorf("%s is out of the table range %s to %s",
+			x.Text('g', 10), keys[0].Text('g', 10), keys[n-1].Text('g', 10))
+	}
+	// greatest key not above x
+	i = sort.Search(n, func(k int) bool { return keys[k].Cmp(x) > 0 }) - 1
+	frac = new(big.Float)
+	if i == n-1 {
+		return i, i, frac, nil
+	}
+	frac.Quo(new(big.Float).Sub(x, keys[i]), new(big.Float).Sub(keys[i+1], keys[i]))
+	switch t.Mode {
+	case Nearest:
+		if frac.Cmp(big.NewFloat(0.5)) >= 0 {
+			i++
+		}
+		return i, i, new(big.Float), nil
+	case Step:
+		return i, i, new(big.Float), nil
+	}
+	if frac.Sign() == 0 {
+		return i, i, frac, nil
+	}
+	return i, i + 1, frac, nil
+}
+
+// lerp returns a + frac*(b - a)
+func lerp(a, b, frac *big.Float) *big.Float {
+	if frac.Sign() == 0 {
+		return a
+	}
+	d := new(big.Float).Sub(b, a)
+	return d.Add(a, d.Mul(d, frac))
+}
+
+// Function returns the function looking up the table: name(column, x),
+// or name(x) when there is a single column, and name(x, y) for a grid.
+// The column is given by its name, so the function is lazy
+func (t *Table) Function() ValueFunction {
+	if t.IsGrid() {
+		return ValueFunction{MinArgs: 2, MaxArgs: 2, Fx: t.gridFunc}
+	}
+	return ValueFunction{MinArgs: 1, MaxArgs: 2, Fx: t.tableFunc, Lazy: true}
+}
+
+func (t *Table) tableFunc(args []Value) (Value, error) {
+	column := 0
+	if len(args) == 2 {
+		name, _ := args[0].(*Expr).Name()
+		if column = slices.Index(t.Columns, name); column < 0 {
+			return nil, fmt.Errorf("unknown column, use %s", strings.Join(t.Columns, ", "))
+		}
+	} else if len(t.Columns) > 1 {
+		return nil, fmt.Errorf("the column is missing, use %s", strings.Join(t.Columns, ", "))
+	}
+	v, err := args[len(args)-1].(*Expr).Eval(nil)
+	if err != nil {
+		return nil, err
+	}
+	x, err := Real(v)
+	if err != nil {
+		return nil, err
+	}
+	return t.Lookup(column, x)
+}
+
+func (t *Table) gridFunc(args []Value) (Value, error) {
+	x, err := Real(args[0])
+	if err != nil {
+		return nil, err
+	}
+	y, err := Real(args[1])
+	if err != nil {
+		return nil, err
+	}
+	return t.LookupGrid(x, y)
+}
@@ -0,0 +1,131 @@
+package esolver
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+const steelCSV = `# thickness in mm, stresses in MPa
+thickness, fy, fu
+16, 355, 470
+40, 345, 470
+63, 335, 450
+`
+
+const gridCSV = `,0,10,20
+0,1,2,3
+10,3,4,5
+`
+
+func newTableSolver(t *testing.T, mode Interpolation) ESolver {
+	t.Helper()
+	e := New()
+	for name, csv := range map[string]string{"steel": steelCSV, "grid": gridCSV} {
+		table, err := ReadTable(strings.NewReader(csv), mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := e.AddFunction(name, table.Function()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return e
+}
+
+func Test_Table_Lookup(t *testing.T) {
+	tests := []struct {
+		mode Interpolation
+		expr string
+		want string
+	}{
+		{Linear, "steel(fy, 16)", "355"},
+		{Linear, "steel(fy, 28)", "350"},
+		{Linear, "steel(fu, 51.5)", "460"},
+		{Linear, "steel(fy, 63)", "335"},
+		{Linear, "2*steel(fy, 40) + 1", "691"},
+		{Nearest, "steel(fy, 27)", "355"},
+		{Nearest, "steel(fy, 28)", "345"},
+		{Step, "steel(fy, 39.9)", "355"},
+		{Step, "steel(fy, 40)", "345"},
+		{Step, "steel(fy, 63)", "335"},
+		// bilinear
+		{Linear, "grid(5, 5)", "2.5"},
+		{Linear, "grid(2.5, 15)", "3"},
+		{Linear, "grid(10, 20)", "5"},
+		{Nearest, "grid(6, 14)", "4"},
+		{Step, "grid(6, 14)", "2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode.String()+" "+tt.expr, func(t *testing.T) {
+			got, err := newTableSolver(t, tt.mode).Solve(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, _, _ := big.ParseFloat(tt.want, 10, floatPrec, big.ToNearestEven)
+			if got.Cmp(want) != 0 {
+				t.Errorf("%s = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Table_LookupErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"steel(fx, 20)", "steel: unknown column, use fy, fu"},
+		{"steel(20)", "steel: the column is missing, use fy, fu"},
+		{"steel(fy, 70)", "steel: 70 is out of the table range 16 to 63"},
+		{"steel 20", "steel: the arguments must be in parentheses"},
+		{"grid(5, -1)", "grid: -1 is out of the table range 0 to 20"},
+	}
+	e := newTableSolver(t, Linear)
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := e.Solve(tt.expr)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("%s error = %v, want %v", tt.expr, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadTableErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want string
+	}{
+		{"no rows", "x,y\n", "a table needs a header, a key column and one row at least"},
+		{"not a number", "x,y\n1,a\n", `row 1: "a" is not a number`},
+		{"decreasing keys", "x,y\n2,1\n1,2\n", "the keys of the rows must be increasing"},
+		{"decreasing column keys", ",2,1\n0,1,2\n", "the keys of the columns must be increasing"},
+		{"digits in a column name", "x,fy2\n1,2\n", `the column name "fy2" must be letters only`},
+		{"empty column name", "x,fy,\n1,2,3\n", `the column name "" must be letters only`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadTable(strings.NewReader(tt.csv), Linear)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("ReadTable() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func Test_esolver_AddFunction(t *testing.T) {
+	e := New()
+	f := ValueFunction{MinArgs: 1, MaxArgs: 1, Fx: func(args []Value) (Value, error) { return args[0], nil }}
+	if err := e.AddFunction("sin", f); err == nil {
+		t.Error("AddFunction(sin) redefined a built-in function")
+	}
+	e.AddValue("same", func() Value { return big.NewFloat(1) })
+	if err := e.AddFunction("same", f); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := e.Solve("same(7)"); err != nil || got.Cmp(big.NewFloat(7)) != 0 {
+		t.Errorf("same(7) = %v, %v, want 7", got, err)
+	}
+}
@@ -5,10 +5,23 @@ type TokenType int
 type Token struct {
 	Type  TokenType
 	Value string
+	// Kind is the kind of a NUMBER written with markers like 45d30' or
+	// 2h 30m, its Value is always the decimal number
+	Kind Kind
+	// Marked is a NUMBER as written with the markers d, m and s like 45d30m,
+	// which are degrees or, when the operand of a date or a duration, days,
+	// minutes and seconds. It is empty for the other numbers
+	Marked string
 }
 
 var eof = rune(0)
 
+// Spaced reports whether the operator is printed between spaces, the
+// operators with lower precedence than * like + and <
+func (t Token) Spaced() bool {
+	return t.Type == OPERATOR && isSpaced(t.Value)
+}
+
 const (
 	NUMBER TokenType = iota
 	LPAREN
@@ -19,4 +32,12 @@ const (
 	WHITESPACE
 	ERROR
 	EOF
+	UNARY // prefix operator, only found after ShuntingYard
+	LBRACKET
+	RBRACKET
+	SEPARATOR
+	ARGS // number of arguments of the next function, only found after ShuntingYard
+	VARIABLE
+	LQUOTE // start of a lazy function argument, only found after ShuntingYard
+	RQUOTE // end of a lazy function argument, only found after ShuntingYard
 )
@@ -0,0 +1,90 @@
+package esolver
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// maxCallDepth limits the nested calls of the user functions, a function
+// redefined calling itself would never stop
+const maxCallDepth = 256
+
+var errCallDepth = fmt.Errorf("more than %d nested calls", maxCallDepth)
+
+// DefineFunction defines the function name(params) = body like
+// hyp(a, b) = sqrt(a^2 + b^2). The parameters shadow the constants with the
+// same name, the other names of the body are looked up when it is called
+func (e *esolver) DefineFunction(name string, params []string, body string) error {
+	name = strings.ToLower(name)
+	params = slices.Clone(params)
+	for i, p := range params {
+		p = strings.ToLower(p)
+		if !isName(p) {
+			return fmt.Errorf("%q is not a valid parameter", p)
+		}
+		if e.elemNames[p] == FUNCTION {
+			return fmt.Errorf("the parameter %s is a function", p)
+		}
+		if slices.Contains(params[:i], p) {
+			return fmt.Errorf("the parameter %s is repeated", p)
+		}
+		params[i] = p
+	}
+	if !isName(name) {
+		return fmt.Errorf("%q is not a valid function name", name)
+	}
+	if slices.Contains(params, name) {
+		return errors.New("a parameter can not have the name of the function")
+	}
+
+	stack, err := e.ParseExpression(body)
+	if err != nil {
+		return err
+	}
+	if len(stack.Values) == 0 {
+		return errors.New("the body of the function is empty")
+	}
+	for _, t := range stack.Values {
+		if t.Type == VARIABLE && !slices.Contains(params, t.Value) {
+			return fmt.Errorf("unknown name %q", t.Value)
+		}
+	}
+	postfix, _ := shuntingYard(stack, e.valueFuncs)
+
+	return e.AddFunction(name, ValueFunction{
+		MinArgs: len(params),
+		MaxArgs: len(params),
+		Fx: func(args []Value) (Value, error) {
+			if e.depth >= maxCallDepth {
+				return nil, errCallDepth
+			}
+			e.depth++
+			defer func() { e.depth-- }()
+
+			scope := make(map[string]Value, len(params))
+			for i, p := range params {
+				scope[p] = args[i]
+			}
+			v, err := e.evalPostfix(postfix, scope)
+			if errors.Is(err, errCallDepth) {
+				// not prefixed by the name of each nested call
+				return nil, errCallDepth
+			}
+			return v, err
+		},
+	})
+}
+
+// isName reports whether s is a name like the ones of the constants, a
+// letter followed by letters and digits
+func isName(s string) bool {
+	for i, ch := range s {
+		if !unicode.IsLetter(ch) && (i == 0 || !unicode.IsDigit(ch)) {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,82 @@
+package esolver
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// floatPrec is the precision in bits of parsed numbers and computed values
+const floatPrec = 256
+
+// Value is the result of an evaluation. Real numbers are represented by
+// *big.Float, complex numbers by *Complex, vectors by Vector, matrices
+// by Matrix, the roots found by solve by *Root, the results of integrate
+// and deriv by *Estimate, the symbolic expressions returned by diff by
+// Node, the exact integers of the combinatorics functions by *big.Int
+// and *Factors, the dates by *Date and the surveying bearings by *Bearing
+type Value any
+
+var (
+	errNotReal = errors.New("result is not a real number")
+	errDomain  = errors.New("argument out of domain")
+)
+
+// Real returns the value as a real number, complex values with a zero
+// imaginary part are accepted
+func Real(v Value) (*big.Float, error) {
+	switch x := v.(type) {
+	case *big.Float:
+		return x, nil
+	case *Complex:
+		if x.Im.Sign() == 0 {
+			return x.Re, nil
+		}
+	case *Root:
+		return x.X, nil
+	case *Estimate:
+		return x.X, nil
+	case *big.Int:
+		return intToFloat(x), nil
+	case *Factors:
+		return intToFloat(x.N), nil
+	case *Bearing:
+		return x.Azimuth, nil
+	}
+	return nil, errNotReal
+}
+
+// isNumeric reports whether the value is a number, a vector or a matrix
+func isNumeric(v Value) bool {
+	return toComplex(v) != nil || isArray(v)
+}
+
+// normalize reduces complex values without imaginary part to real numbers
+func normalize(v Value) Value {
+	if c, ok := v.(*Complex); ok && c.Im.Sign() == 0 {
+		return c.Re
+	}
+	return v
+}
+
+func typeName(v Value) string {
+	switch v.(type) {
+	case *big.Float:
+		return "real"
+	case *Complex:
+		return "complex"
+	case Vector:
+		return "vector"
+	case Matrix:
+		return "matrix"
+	case *Bool:
+		return "boolean"
+	case Node:
+		return "expression"
+	case *Date:
+		return "date"
+	case *Bearing:
+		return "bearing"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,49 @@
+package ecalc
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// reDefinition matches the definition of a function like
+// hyp(a, b) = sqrt(a^2 + b^2), the = is not the start of ==
+var reDefinition = regexp.MustCompile(`^\s*(\pL[\pL\pN]*)\s*\(\s*(\pL[\pL\pN]*(?:\s*,\s*\pL[\pL\pN]*)*)\s*\)\s*=([^=].*)$`)
+
+// DefineFunction defines the function of a definition like
+// hyp(a, b) = sqrt(a^2 + b^2), defining a function with the name of a
+// variable or a table replaces it
+func (e *ECalc) DefineFunction(def string) error {
+	return e.define(def, true).Error
+}
+
+// define returns the result of a definition, it has the definition as the
+// expression and no value. The function is only defined when assign is
+// true, otherwise it is a preview
+func (e *ECalc) define(def string, assign bool) *Result {
+	c := &Result{Expression: def}
+	m := reDefinition.FindStringSubmatch(def)
+	if m == nil {
+		c.Error = errors.New("the definition must be like f(x) = x^2")
+		return c
+	}
+	name, body := strings.ToLower(m[1]), m[3]
+	params := strings.Split(m[2], ",")
+	for i, p := range params {
+		params[i] = strings.TrimSpace(p)
+	}
+	c.Function = name
+	if !assign {
+		return c
+	}
+
+	if err := e.solver.DefineFunction(name, params, body); err != nil {
+		c.Error = err
+		return c
+	}
+	delete(e.variables, name)
+	delete(e.tables, name)
+	e.functions[name] = true
+	c.StackExpr, c.Error = e.solver.ParseExpression(def)
+	return c
+}
@@ -0,0 +1,143 @@
+package ecalc
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// RepresentationNames lists the names of the representations in the order
+// they are printed
+var RepresentationNames = []string{
+	"decimal", "fraction", "dms", "hms", "scientific", "engineering", "hex", "bin", "exact",
+}
+
+// significantDigits of the scientific and engineering representations
+const significantDigits = 20
+
+// maxDenominator limits the fractions, values without a shorter fraction
+// have no fraction representation
+var maxDenominator = big.NewInt(1e6)
+
+// Representations returns the real answer written in every format, decimal,
+// fraction, dms, scientific, engineering, the exact digits, hms for the
+// durations and hex and bin for the integers. It is nil when the answer is
+// not a real number
+func (c *Result) Representations() map[string]string {
+	if c.Error != nil || c.Value == nil {
+		return nil
+	}
+	value := c.Value
+	if value.IsInf() {
+		// the infinities have no digits, fraction, dms nor hms
+		return map[string]string{"decimal": value.String(), "exact": value.String()}
+	}
+	r := map[string]string{
+		"decimal":     formatRecurring(value, 20),
+		"dms":         convertDMS(value, c.dmsFormat()),
+		"scientific":  formatScientific(value),
+		"engineering": formatEngineering(value),
+		"exact":       value.Text('f', -1),
+	}
+	if f, ok := formatFraction(value); ok {
+		r["fraction"] = f
+	}
+	if c.Time {
+		r["hms"] = formatHMS(value)
+	}
+	if value.IsInt() {
+		n, _ := value.Int(nil)
+		r["hex"] = fmt.Sprintf("%#x", n)
+		r["bin"] = fmt.Sprintf("%#b", n)
+	}
+	return r
+}
+
+// formatFraction returns the shortest fraction equal to the value within
+// its precision, found by the convergents of its continued fraction, like
+// 1/3 or -7/4. Integers have no fraction
+func formatFraction(value *big.Float) (string, bool) {
+	if value.IsInt() || value.IsInf() {
+		return "", false
+	}
+	x, _ := new(big.Float).Abs(value).Rat(nil)
+	// the error allowed is a few units of the last bit of the value
+	tolerance := new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Lsh(big.NewInt(1), max(value.Prec(), 8)-4))
+	tolerance.Mul(tolerance, x)
+
+	h0, h1 := big.NewInt(0), big.NewInt(1)
+	k0, k1 := big.NewInt(1), big.NewInt(0)
+	rest := new(big.Rat).Set(x)
+	for rest.Sign() != 0 {
+		a := new(big.Int).Quo(rest.Num(), rest.Denom())
+		h0, h1 = h1, new(big.Int).Add(new(big.Int).Mul(a, h1), h0)
+		k0, k1 = k1, new(big.Int).Add(new(big.Int).Mul(a, k1), k0)
+		if k1.Cmp(maxDenominator) > 0 {
+			return "", false
+		}
+		diff := new(big.Rat).Sub(x, new(big.Rat).SetFrac(h1, k1))
+		if diff.Abs(diff).Cmp(tolerance) <= 0 {
+			break
+		}
+		rest.Sub(rest, new(big.Rat).SetInt(a))
+		rest.Inv(rest)
+	}
+	sign := ""
+	if value.Sign() < 0 {
+		sign = "-"
+	}
+	return fmt.Sprintf("%s%s/%s", sign, h1, k1), true
+}
+
+// formatScientific writes the value with the digits of its precision up to
+// 20 significant digits, like 1.5e+03
+func formatScientific(value *big.Float) string {
+	mantissa, exp := scientificParts(value)
+	if len(mantissa) > 1 {
+		mantissa = mantissa[:1] + "." + mantissa[1:]
+	}
+	return fmt.Sprintf("%s%se%+03d", sign(value), mantissa, exp)
+}
+
+// formatEngineering writes the value with an exponent multiple of 3, like
+// 15e+03 or 150e-06
+func formatEngineering(value *big.Float) string {
+	digits, exp := scientificParts(value)
+	shift := exp % 3
+	if shift < 0 {
+		shift += 3
+	}
+	if len(digits) < shift+1 {
+		digits += strings.Repeat("0", shift+1-len(digits))
+	}
+	mantissa := digits[:shift+1]
+	if len(digits) > shift+1 {
+		mantissa += "." + digits[shift+1:]
+	}
+	return fmt.Sprintf("%s%se%+03d", sign(value), mantissa, exp-shift)
+}
+
+// scientificParts returns the significant digits of the value without the
+// trailing zeros and its decimal exponent, 1500 is 15 and 3
+func scientificParts(value *big.Float) (string, int) {
+	x := new(big.Float).Abs(value)
+	s := x.Text('e', -1)
+	if mantissa, _, _ := strings.Cut(s, "e"); len(mantissa) > significantDigits+1 {
+		s = x.Text('e', significantDigits-1)
+	}
+	mantissa, exp, _ := strings.Cut(s, "e")
+	n, _ := strconv.Atoi(exp)
+	digits := strings.TrimRight(strings.Replace(mantissa, ".", "", 1), "0")
+	if digits == "" {
+		return "0", 0
+	}
+	return digits, n
+}
+
+func sign(value *big.Float) string {
+	if value.Sign() < 0 {
+		return "-"
+	}
+	return ""
+}
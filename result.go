@@ -3,64 +3,399 @@ package ecalc
 import (
 	"fmt"
 	"io"
+	"math"
 	"math/big"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/rodcorsi/ecalc/esolver"
 )
 
 type Result struct {
-	Value       *big.Float
-	Degree      bool
+	// Value is the answer when it is a real number, otherwise nil
+	Value *big.Float
+	// Answer is the real or complex answer
+	Answer esolver.Value
+	// Kind is the kind of the answer found by the evaluation, an angle or a
+	// duration
+	Kind esolver.Kind
+	// Degree shows real results as angles like 45d30'0"
+	Degree bool
+	// DMSPrecision is the number of decimals of the seconds of the angles
+	DMSPrecision int
+	// DMSSymbols shows the angles with the symbols ° ′ ″ instead of d ' "
+	DMSSymbols bool
+	// Time shows real results as durations like 2h 35m 10s
+	Time  bool
+	Polar bool
+	// Radians shows the angle of the polar form in radians
+	Radians bool
+	// Money shows real results with two decimals and thousands separators
+	Money       bool
 	Error       error
 	Writer      io.Writer
 	EngNotation bool
 	Partial     bool
-	Expression  string
-	StackExpr   esolver.Stack
+	// Warnings tell the operators and the ans inserted in the expression
+	Warnings   []string
+	Expression string
+	StackExpr  esolver.Stack
+	// Name is the variable assigned by an expression like x = 2*y
+	Name string
+	// Function is the function defined by an expression like f(x) = x^2,
+	// the definition has no value
+	Function string
 }
 
+// FormatExpression prints the tokens of the expression, the arguments of
+// the functions called without parentheses are enclosed in parentheses as
+// they were bound by the solver, so sin30^2 is printed sin(30)^2
 func (e *Result) FormatExpression(printer func(value string, t esolver.Token)) {
+	args := implicitArguments(e.StackExpr)
+	closeParens := make(map[int]int)
+	for _, end := range args {
+		closeParens[end]++
+	}
+
 	lastType := esolver.TokenType(-1)
-	for _, v := range e.StackExpr.Values {
-		closeParen := false
-		if lastType == esolver.FUNCTION && (v.Type == esolver.NUMBER || v.Type == esolver.CONSTANT) {
-			printer("(", v)
-			closeParen = true
-		}
+	for i, v := range e.StackExpr.Values {
+		prefix := lastType == -1 || lastType == esolver.OPERATOR || lastType == esolver.FUNCTION ||
+			lastType == esolver.LPAREN || lastType == esolver.LBRACKET || lastType == esolver.SEPARATOR
 
-		if v.Type == esolver.FUNCTION || v.Type == esolver.CONSTANT {
-			printer(v.Value, v)
-		} else if v.Type == esolver.NUMBER {
-			printer(v.Value, v)
-		} else if v.Value == "+" || v.Value == "-" {
+		if v.Spaced() && !prefix {
 			printer(" "+v.Value+" ", v)
+		} else if v.Value == "not" {
+			printer(v.Value+" ", v)
+		} else if v.Type == esolver.SEPARATOR {
+			printer(v.Value+" ", v)
+		} else if v.Marked != "" {
+			printer(v.Marked, v)
+		} else if v.Type == esolver.NUMBER {
+			printer(v.Value+v.Kind.Marker(), v)
 		} else {
 			printer(v.Value, v)
 		}
-		if closeParen {
-			printer(")", v)
+		if _, ok := args[i]; ok {
+			printer("(", esolver.Token{Type: esolver.LPAREN, Value: "("})
+		}
+		for range closeParens[i] {
+			printer(")", esolver.Token{Type: esolver.RPAREN, Value: ")"})
 		}
 		lastType = v.Type
 	}
 }
 
+// implicitArguments returns for each function called without parentheses
+// the index of its name and of the last token of its argument. They are
+// found evaluating the postfix of the expression with the index of the
+// last token of each operand, so the argument is the one bound by the solver
+func implicitArguments(s esolver.Stack) map[int]int {
+	tokens := s.Values
+	opening := make(map[int]int) // index of the group closed at each index
+	var groups []int
+	for i, t := range tokens {
+		switch t.Type {
+		case esolver.LPAREN, esolver.LBRACKET:
+			groups = append(groups, i)
+		case esolver.RPAREN, esolver.RBRACKET:
+			if len(groups) > 0 {
+				opening[i] = groups[len(groups)-1]
+				groups = groups[:len(groups)-1]
+			}
+		}
+	}
+
+	ends := make(map[int]int)
+	var last []int
+	pop := func(n int) int {
+		end := -1
+		for len(last) > 0 && n > 0 {
+			end = max(end, last[len(last)-1])
+			last = last[:len(last)-1]
+			n--
+		}
+		return end
+	}
+	nArgs := 1
+	postfix, index := esolver.ShuntingYardIndex(s)
+	for k, t := range postfix.Values {
+		i := index[k]
+		switch t.Type {
+		case esolver.NUMBER, esolver.CONSTANT, esolver.VARIABLE:
+			last = append(last, i)
+		case esolver.UNARY:
+			last = append(last, max(i, pop(1)))
+		case esolver.OPERATOR:
+			last = append(last, pop(2))
+		case esolver.ARGS:
+			nArgs, _ = strconv.Atoi(t.Value)
+		case esolver.RBRACKET:
+			pop(nArgs)
+			nArgs = 1
+			last = append(last, i)
+		case esolver.FUNCTION:
+			end := pop(nArgs)
+			nArgs = 1
+			// the groups opened inside the argument close in it
+			for end >= 0 && end+1 < len(tokens) {
+				if j, ok := opening[end+1]; !ok || j < i {
+					break
+				}
+				end++
+			}
+			if end > i && tokens[i+1].Type != esolver.LPAREN {
+				ends[i] = end
+			}
+			last = append(last, max(i, end))
+		}
+	}
+	return ends
+}
+
 func (c *Result) String() string {
 	if c.Error != nil {
 		return c.Error.Error()
+	} else if z, ok := c.Answer.(*esolver.Complex); ok {
+		return formatComplex(z, c.Polar, c.Radians)
+	} else if v, ok := c.Answer.(esolver.Vector); ok {
+		return formatVector(v)
+	} else if m, ok := c.Answer.(esolver.Matrix); ok {
+		return formatMatrix(m)
+	} else if r, ok := c.Answer.(*esolver.Root); ok {
+		return fmt.Sprintf("%s (residual %.3g, %d iterations)", formatReal(r.X), r.Residual, r.Iterations)
+	} else if e, ok := c.Answer.(*esolver.Estimate); ok {
+		return fmt.Sprintf("%s (error %.3g)", formatReal(e.X), e.Error)
+	} else if b, ok := c.Answer.(*esolver.Bool); ok {
+		return formatBool(b)
+	} else if f, ok := c.Answer.(*esolver.Factors); ok {
+		return f.String()
+	} else if d, ok := c.Answer.(*esolver.Date); ok {
+		return d.String()
+	} else if b, ok := c.Answer.(*esolver.Bearing); ok {
+		return formatBearing(b, c.dmsFormat())
+	} else if c.Value == nil {
+		return fmt.Sprint(c.Answer)
+	} else if c.Money && !c.Value.IsInf() {
+		return formatMoney(c.Value)
+	} else if c.Time {
+		return formatHMS(c.Value)
 	} else if c.Degree {
-		return convertDMS(c.Value)
+		return convertDMS(c.Value, c.dmsFormat())
+	} else if n, ok := c.Answer.(*big.Int); ok {
+		// exact integers print every digit
+		return n.String()
 	} else if c.EngNotation {
 		return fmt.Sprintf("%e", c.Value)
 	}
 	return formatRecurring(c.Value, 20)
 }
-func convertDMS(value *big.Float) string {
-	v, _ := value.Float64()
-	d := int64(v)
-	m := (v - float64(d)) * 60.0
-	s := (m - float64(int64(m))) * 60.0
-	return fmt.Sprintf(`%vd%2d'%.5f"`, d, int64(m), s)
+
+// formatBool shows the compared values of a comparison
+func formatBool(b *esolver.Bool) string {
+	if b.Op == "" {
+		return b.String()
+	}
+	return fmt.Sprintf("%s (%s %s %s)", b, formatReal(b.X), b.Op, formatReal(b.Y))
+}
+
+func formatComplex(z *esolver.Complex, polar, radians bool) string {
+	if polar {
+		arg := z.Arg()
+		if radians {
+			arg.Mul(arg, big.NewFloat(math.Pi/180))
+		}
+		return formatReal(z.Abs()) + "∠" + formatReal(arg)
+	}
+	im := new(big.Float).Abs(z.Im)
+	if z.Re.Sign() == 0 {
+		if z.Im.Sign() < 0 {
+			return "-" + formatReal(im) + "i"
+		}
+		return formatReal(im) + "i"
+	}
+	sign := " + "
+	if z.Im.Sign() < 0 {
+		sign = " - "
+	}
+	return formatReal(z.Re) + sign + formatReal(im) + "i"
+}
+
+func formatVector(v esolver.Vector) string {
+	items := make([]string, len(v))
+	for i, x := range v {
+		items[i] = formatElement(x)
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// formatMatrix writes each row in a line with the columns right aligned
+func formatMatrix(m esolver.Matrix) string {
+	cells := make([][]string, len(m))
+	widths := make([]int, len(m[0]))
+	for i, row := range m {
+		cells[i] = make([]string, len(row))
+		for j, x := range row {
+			cells[i][j] = formatElement(x)
+			widths[j] = max(widths[j], textWidth(cells[i][j]))
+		}
+	}
+
+	var sb strings.Builder
+	for i, row := range cells {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("[")
+		for j, cell := range row {
+			sb.WriteString(" ")
+			sb.WriteString(strings.Repeat(" ", widths[j]-textWidth(cell)))
+			sb.WriteString(cell)
+		}
+		sb.WriteString(" ]")
+	}
+	return sb.String()
+}
+
+// formatElement shows vector and matrix elements with float64 precision
+// to keep them short
+func formatElement(x *big.Float) string {
+	return formatReal(new(big.Float).SetPrec(53).Set(x))
+}
+
+// textWidth counts the printed characters ignoring the overline marks
+func textWidth(s string) int {
+	return utf8.RuneCountInString(s) - strings.Count(s, "\u0305")
+}
+
+func formatReal(value *big.Float) string {
+	if isEngNotation(value) {
+		return fmt.Sprintf("%e", value)
+	}
+	return formatRecurring(value, 20)
+}
+
+// formatMoney rounds to cents, half away from zero, and groups the
+// thousands as 1,234,567.89. The value is first rounded to 30 decimals so
+// 2.675 rounds up even when its binary value is a bit lower, the value
+// must be finite
+func formatMoney(value *big.Float) string {
+	r, _ := new(big.Rat).SetString(value.Text('f', 30))
+	r.Mul(r, big.NewRat(100, 1))
+	num := new(big.Int).Abs(r.Num())
+	den := r.Denom()
+	// cents = (2*num + den) / (2*den)
+	cents := new(big.Int).Lsh(num, 1)
+	cents.Add(cents, den)
+	cents.Quo(cents, new(big.Int).Lsh(den, 1))
+
+	units, frac := new(big.Int).QuoRem(cents, big.NewInt(100), new(big.Int))
+	digits := units.String()
+	var sb strings.Builder
+	if r.Sign() < 0 && cents.Sign() != 0 {
+		sb.WriteString("-")
+	}
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteRune(d)
+	}
+	fmt.Fprintf(&sb, ".%02d", frac.Int64())
+	return sb.String()
+}
+
+// dmsFormat is the format of the angles in degrees, minutes and seconds
+type dmsFormat struct {
+	// Precision is the number of decimals of the seconds
+	Precision int
+	// Symbols writes 45°30′15″ instead of 45d30'15"
+	Symbols bool
+}
+
+func (c *Result) dmsFormat() dmsFormat {
+	return dmsFormat{Precision: c.DMSPrecision, Symbols: c.DMSSymbols}
+}
+
+// convertDMS writes degrees as 45d30'15.00000" with the seconds rounded
+// half up to the decimals of the format, carrying the rounded seconds and
+// minutes, so 359d59'59.9999999" is 360d 0'0.00000". The rounding is
+// exact, the value is never converted to float64
+func convertDMS(value *big.Float, format dmsFormat) string {
+	if value.IsInf() {
+		return formatReal(value)
+	}
+	precision := format.Precision
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(precision)), nil)
+	factor := new(big.Int).Mul(scale, big.NewInt(3600))
+	// the product and the half added to round are exact with these bits
+	prec := max(value.Prec(), 64) + uint(factor.BitLen()) + 2
+	units := new(big.Float).SetPrec(prec).Abs(value)
+	units.Mul(units, new(big.Float).SetInt(factor))
+	units.Add(units, big.NewFloat(0.5))
+	n, _ := units.Int(nil)
+
+	sign := ""
+	if value.Sign() < 0 && n.Sign() != 0 {
+		sign = "-"
+	}
+	minute := new(big.Int).Mul(scale, big.NewInt(60))
+	d, n := new(big.Int).QuoRem(n, new(big.Int).Mul(minute, big.NewInt(60)), new(big.Int))
+	m, n := new(big.Int).QuoRem(n, minute, new(big.Int))
+	s, frac := new(big.Int).QuoRem(n, scale, new(big.Int))
+	seconds := s.String()
+	if precision > 0 {
+		seconds += fmt.Sprintf(".%0*s", precision, frac)
+	}
+	if format.Symbols {
+		return fmt.Sprintf(`%s%s°%2d′%s″`, sign, d, m.Int64(), seconds)
+	}
+	return fmt.Sprintf(`%s%sd%2d'%s"`, sign, d, m.Int64(), seconds)
+}
+
+// formatBearing writes a bearing as N45d30'0.00000"E, preceded by the
+// distance of an inverse
+func formatBearing(b *esolver.Bearing, format dmsFormat) string {
+	from, angle, to := b.Quadrant()
+	s := from + convertDMS(angle, format) + to
+	if b.Distance != nil {
+		return formatElement(b.Distance) + " " + s
+	}
+	return s
+}
+
+// formatHMS writes hours as 2h 35m 10.5s rounded to milliseconds, adding
+// the days when there is one at least, like 3d 4h 0m 0s
+func formatHMS(value *big.Float) string {
+	if value.IsInf() {
+		return formatReal(value)
+	}
+	ms := new(big.Float).Mul(value, big.NewFloat(3600000))
+	if ms.Sign() < 0 {
+		ms.Sub(ms, big.NewFloat(0.5))
+	} else {
+		ms.Add(ms, big.NewFloat(0.5))
+	}
+	n, _ := ms.Int(nil)
+	sign := ""
+	if n.Sign() < 0 {
+		sign = "-"
+		n.Neg(n)
+	}
+	parts := []int64{86400000, 3600000, 60000, 1000}
+	values := make([]*big.Int, len(parts))
+	for i, p := range parts {
+		values[i], n = new(big.Int).QuoRem(n, big.NewInt(p), new(big.Int))
+	}
+	s := values[3].String()
+	if n.Sign() != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%03d", n.Int64()), "0")
+	}
+	hms := fmt.Sprintf("%sh %sm %ss", values[1], values[2], s)
+	if values[0].Sign() != 0 {
+		return fmt.Sprintf("%s%sd %s", sign, values[0], hms)
+	}
+	return sign + hms
 }
 
 func formatRecurring(n *big.Float, precision int) string {
@@ -71,7 +406,7 @@ func formatRecurring(n *big.Float, precision int) string {
 	var sign string
 	if n.Sign() < 0 {
 		sign = "-"
-		n.Abs(n)
+		n = new(big.Float).Abs(n)
 	}
 
 	s := n.Text('f', precision)
@@ -1,8 +1,12 @@
 package ecalc
 
 import (
+	"maps"
 	"math/big"
+	"strings"
 	"testing"
+
+	"github.com/rodcorsi/ecalc/esolver"
 )
 
 func TestFormatRecurring(t *testing.T) {
@@ -32,3 +36,298 @@ func TestFormatRecurring(t *testing.T) {
 		})
 	}
 }
+
+func TestResultConstant(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"10in", "254"},
+		{"2pi", "6.283185307179586"},
+		{"e*1", "2.718281828459045"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if got := NewECalc().Eval(tt.expr).String(); got != tt.want {
+				t.Errorf("Eval(%q).String() = %q, want %q", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultComplex(t *testing.T) {
+	tests := []struct {
+		name  string
+		expr  string
+		polar bool
+		want  string
+	}{
+		{"rectangular", "3+4i", false, "3 + 4i"},
+		{"negative imaginary", "3-4i", false, "3 - 4i"},
+		{"imaginary", "2i", false, "2i"},
+		{"negative imaginary only", "-(2i)", false, "-2i"},
+		{"polar", "3+4i", true, "5∠53.13010235415598"},
+		{"polar radians", "3+4i", true, "5∠0.9272952180016122"},
+		{"real part collapse", "i*i", false, "-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewECalc()
+			e.Polar = tt.polar
+			if strings.HasSuffix(tt.name, "radians") {
+				e.SetAngleUnit(esolver.Radians)
+			}
+			if got := e.Eval(tt.expr).String(); got != tt.want {
+				t.Errorf("Eval(%q).String() = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultArray(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"[1,2,3]", "[1, 2, 3]"},
+		{"[1,2]/4", "[0.25, 0.5]"},
+		{"[[1,2],[3,4]]", "[ 1 2 ]\n[ 3 4 ]"},
+		{"[[1,-20],[300,4]]", "[   1 -20 ]\n[ 300   4 ]"},
+		{"inv([[1,2],[3,4]])", "[  -2    1 ]\n[ 1.5 -0.5 ]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if got := NewECalc().Eval(tt.expr).String(); got != tt.want {
+				t.Errorf("Eval(%q).String() = %q, want %q", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultInteger(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"factorial(25)", "15511210043330985984000000"},
+		{"ncr(100, 50)", "100891344545564193334812497256"},
+		{"gcd(12, 18)", "6"},
+		{"factor(360)", "2^3 * 3^2 * 5"},
+		{"factorial(20)/factorial(18)", "380"},
+		{"99999999999999999999*99999999999999999999", "9999999999999999999800000000000000000001"},
+		{"2^70 + 1", "1180591620717411303425"},
+		{"factorial(0.1*30)", "6"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if got := NewECalc().Eval(tt.expr).String(); got != tt.want {
+				t.Errorf("Eval(%q).String() = %q, want %q", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultMoney(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"pv(0.05/12, 360, -1000)", "186,281.62"},
+		{"pmt(0.08/12, 60, 20000)", "-405.53"},
+		{"2.675", "2.68"},
+		{"999.995", "1,000.00"},
+		{"-0.001", "0.00"},
+		{"12", "12.00"},
+		{"10^9", "1,000,000,000.00"},
+		{"2+3i", "2 + 3i"},
+		{"1/0", "+Inf"},
+		{"-1/0", "-Inf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e := NewECalc()
+			e.Money = true
+			if got := e.Eval(tt.expr).String(); got != tt.want {
+				t.Errorf("Eval(%q).String() = %q, want %q", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultRepresentations(t *testing.T) {
+	tests := []struct {
+		expr string
+		want map[string]string
+	}{
+		{"1/3", map[string]string{
+			"decimal": "0.\u0305" + "3", "fraction": "1/3", "dms": `0d20'0.00000"`,
+			"scientific": "3.3333333333333333333e-01", "engineering": "333.33333333333333333e-03",
+			"exact": "0.333333333333333333333333333333333333333333333333333333333333333333333333333335",
+		}},
+		{"255", map[string]string{
+			"decimal": "255", "dms": `255d 0'0.00000"`, "scientific": "2.55e+02", "engineering": "255e+00",
+			"hex": "0xff", "bin": "0b11111111", "exact": "255",
+		}},
+		{"-0.000015", map[string]string{
+			"decimal": "-0.000015", "fraction": "-3/200000", "dms": `-0d 0'0.05400"`,
+			"scientific": "-1.5e-05", "engineering": "-15e-06", "exact": "-0.000015",
+		}},
+		{"pi", map[string]string{
+			"decimal": "3.141592653589793", "dms": `3d 8'29.73355"`, "scientific": "3.141592653589793e+00",
+			"engineering": "3.141592653589793e+00", "exact": "3.141592653589793",
+		}},
+		{"2h 30m", map[string]string{
+			"decimal": "2.5", "fraction": "5/2", "dms": `2d30'0.00000"`, "hms": "2h 30m 0s",
+			"scientific": "2.5e+00", "engineering": "2.5e+00", "exact": "2.5",
+		}},
+		{"[1, 2]", nil},
+		{"1/0", map[string]string{"decimal": "+Inf", "exact": "+Inf"}},
+		{"-2h/0", map[string]string{"decimal": "-Inf", "exact": "-Inf"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got := NewECalc().Eval(tt.expr).Representations()
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("Representations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultTime(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"2h 35m 10s + 45m", "3h 20m 10s"},
+		{"1.2345678h", "1h 14m 4.444s"},
+		{"2h - 3h", "-1h 0m 0s"},
+		{"1d 2h", "1d 2h 0m 0s"},
+		{"2026-12-25 - 2026-10-18", "68d 0h 0m 0s"},
+		{"days(2026-12-25 - 2026-10-18)", "68"},
+		{"2026-10-18 + 90d", "2027-01-16"},
+		{"45d30'", "45d30'0.00000\""},
+		{"1/0*1d", "+Inf"},
+		{"-1/0*1d", "-Inf"},
+		{"45m + 2h", "2h 45m 0s"},
+		{"2h/0", "+Inf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if got := NewECalc().Eval(tt.expr).String(); got != tt.want {
+				t.Errorf("Eval(%q).String() = %q, want %q", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertDMS(t *testing.T) {
+	tests := []struct {
+		value     float64
+		precision int
+		want      string
+	}{
+		{45.5, 5, `45d30'0.00000"`},
+		{6.316482850, 5, `6d18'59.33826"`},
+		{359.99999999999, 5, `360d 0'0.00000"`},
+		{29.999999, 2, `30d 0'0.00"`},
+		{45.50833, 0, `45d30'30"`},
+		{-10.25, 1, `-10d15'0.0"`},
+		{-0.5, 5, `-0d30'0.00000"`},
+		{-0.000000001, 5, `0d 0'0.00000"`},
+		{59.999999999, 3, `60d 0'0.000"`},
+		{0.0083333333, 2, `0d 0'30.00"`},
+	}
+	for _, tt := range tests {
+		if got := convertDMS(big.NewFloat(tt.value), dmsFormat{Precision: tt.precision}); got != tt.want {
+			t.Errorf("convertDMS(%v, %d) = %q, want %q", tt.value, tt.precision, got, tt.want)
+		}
+	}
+
+	// 1/3 degree is exactly 20 minutes, the float64 value 0.3333 is not
+	third, _, _ := big.ParseFloat("0.33333333333333333333333333333333333333333333", 10, 256, big.ToNearestEven)
+	if got := convertDMS(third, dmsFormat{Precision: 30}); got != `0d20'0.000000000000000000000000000000"` {
+		t.Errorf("convertDMS(1/3, 30) = %q", got)
+	}
+	if got := convertDMS(big.NewFloat(-45.5042), dmsFormat{Precision: 2, Symbols: true}); got != `-45°30′15.12″` {
+		t.Errorf("convertDMS(-45.5042, symbols) = %q", got)
+	}
+	if got := convertDMS(new(big.Float).SetInf(true), dmsFormat{Precision: 5}); got != "-Inf" {
+		t.Errorf("convertDMS(-Inf) = %q", got)
+	}
+}
+
+func TestResultBearing(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"bearing(135.5)", `S44d30'0.00000"E`},
+		{"bearing(N45d30'15\"W)", `N45d30'15.00000"W`},
+		{"inverse(0, 0, 100, 100)", `141.4213562373095 N45d 0'0.00000"E`},
+		{"S45d30'E", `134d30'0.00000"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if got := NewECalc().Eval(tt.expr).String(); got != tt.want {
+				t.Errorf("Eval(%q).String() = %q, want %q", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImplicitArguments(t *testing.T) {
+	tests := []struct {
+		expr string
+		want map[int]int
+	}{
+		{"sin30^2", map[int]int{0: 1}},
+		{"sin-30^2", map[int]int{0: 4}},
+		{"sin(30)", map[int]int{}},
+		{"sin cos 0 + 1", map[int]int{0: 2, 1: 2}},
+		{"2^sqrt 4*3", map[int]int{2: 3}},
+		{"dot(sin 30, 2)", map[int]int{2: 3}},
+		{"sin -(1 + 2)*3", map[int]int{0: 6}},
+		{"sin cos(30)", map[int]int{0: 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			r := NewECalc().Eval(tt.expr)
+			if got := implicitArguments(r.StackExpr); !maps.Equal(got, tt.want) {
+				t.Errorf("implicitArguments() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatExpression(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"5+2", "5 + 2"},
+		{"5tan45", "5*tan(45)"},
+		{"sin30^2", "sin(30)^2"},
+		{"sin(30^2)", "sin(30^2)"},
+		{"sin-30^2", "sin(-30^2)"},
+		{"sqrt4+sqrt9", "sqrt(4) + sqrt(9)"},
+		{"sin cos 0", "sin(cos(0))"},
+		{"2^sqrt4*3", "2^sqrt(4)*3"},
+		{"sqrt [4, 9]", "sqrt([4, 9])"},
+		{"2*-3", "2*-3"},
+		{"*2", "ans*2"},
+		{"45d30'+1", "45.5° + 1"},
+		{"2h 30m", "2.5h"},
+		{"2026-10-18 + 90d", "2026-10-18 + 90d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			r := NewECalc().Eval(tt.expr)
+			var sb strings.Builder
+			r.FormatExpression(func(value string, _ esolver.Token) { sb.WriteString(value) })
+			if got := sb.String(); got != tt.want {
+				t.Errorf("FormatExpression() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
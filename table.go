@@ -0,0 +1,51 @@
+package ecalc
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/rodcorsi/ecalc/esolver"
+)
+
+// Table is a lookup table loaded from a CSV file
+type Table struct {
+	Name string
+	Path string
+	*esolver.Table
+}
+
+// LoadTable reads the CSV file and defines the function name looking up
+// the table, loading a table with the same name replaces it
+func (e *ECalc) LoadTable(path, name string, mode esolver.Interpolation) (*Table, error) {
+	name = strings.ToLower(name)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	t, err := esolver.ReadTable(f, mode)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := e.solver.AddFunction(name, t.Function()); err != nil {
+		return nil, err
+	}
+	delete(e.variables, name)
+	delete(e.functions, name)
+	table := &Table{Name: name, Path: path, Table: t}
+	e.tables[name] = table
+	return table, nil
+}
+
+// Tables lists the loaded tables sorted by name
+func (e *ECalc) Tables() []*Table {
+	tables := make([]*Table, 0, len(e.tables))
+	for _, t := range e.tables {
+		tables = append(tables, t)
+	}
+	slices.SortFunc(tables, func(a, b *Table) int { return strings.Compare(a.Name, b.Name) })
+	return tables
+}